@@ -0,0 +1,45 @@
+package filex
+
+import (
+	"os"
+	"sync"
+)
+
+// SafeAppender 并发安全的文件追加写入器
+// 持有以 O_APPEND 模式打开的文件句柄, 每次 Append 在互斥锁保护下完整写入, 同一进程内的多次写入不会交错
+type SafeAppender struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+// NewAppender 以追加模式打开文件(不存在时创建, 并自动创建所在目录), 返回 SafeAppender
+func NewAppender(path string) (*SafeAppender, error) {
+	f, err := Open(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND)
+	if err != nil {
+		return nil, err
+	}
+	return &SafeAppender{f: f}, nil
+}
+
+// Append 追加写入 data, 关闭后调用返回 os.ErrClosed
+func (a *SafeAppender) Append(data []byte) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.f == nil {
+		return os.ErrClosed
+	}
+	_, err := a.f.Write(data)
+	return err
+}
+
+// Close 关闭文件句柄, 可重复调用
+func (a *SafeAppender) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.f == nil {
+		return nil
+	}
+	err := a.f.Close()
+	a.f = nil
+	return err
+}
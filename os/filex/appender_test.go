@@ -0,0 +1,48 @@
+package filex
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSafeAppender(t *testing.T) {
+	assert := assert.New(t)
+	p := filepath.Join(t.TempDir(), "logs", "app.log")
+	a, err := NewAppender(p)
+	assert.Nil(err)
+
+	const workers, each, size = 8, 10, 256 * 1024
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(c byte) {
+			defer wg.Done()
+			buf := bytes.Repeat([]byte{c}, size)
+			for j := 0; j < each; j++ {
+				assert.Nil(a.Append(buf))
+			}
+		}(byte('a' + i))
+	}
+	wg.Wait()
+	assert.Nil(a.Close())
+	assert.Nil(a.Close())
+	assert.Equal(os.ErrClosed, a.Append([]byte("x")))
+
+	data, err := os.ReadFile(p)
+	assert.Nil(err)
+	assert.Equal(workers*each*size, len(data))
+	counts := make(map[byte]int)
+	for off := 0; off < len(data); off += size {
+		block := data[off : off+size]
+		assert.Equal(bytes.Repeat(block[:1], size), block, "block at %d is interleaved", off)
+		counts[block[0]]++
+	}
+	for i := 0; i < workers; i++ {
+		assert.Equal(each, counts[byte('a'+i)])
+	}
+}
@@ -0,0 +1,58 @@
+package filex
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// writeFileAtomic 先写入同目录下的临时文件, 同步后再重命名为 path, 实现原子替换
+// 写入过程中出错时 path 保持不变; 已存在的文件保留原有权限, 新文件使用 perm
+// path 为符号链接时替换其指向的文件, 链接本身保持不变
+func writeFileAtomic(path string, perm os.FileMode, write func(w io.Writer) error) error {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	if err := mkdirParent(path); err != nil {
+		return err
+	}
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(Dir(path), "."+Basename(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	name := tmp.Name()
+	bw := bufio.NewWriterSize(tmp, copyBufferSize)
+	err = write(bw)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(name, perm)
+	}
+	if err == nil {
+		err = os.Rename(name, path)
+	}
+	if err != nil {
+		os.Remove(name)
+		return err
+	}
+	return nil
+}
+
+// putContentsAtomic 原子替换文件内容
+func putContentsAtomic(path string, data []byte) error {
+	return writeFileAtomic(path, 0666, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
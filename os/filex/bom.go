@@ -0,0 +1,78 @@
+package filex
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// bom 字节顺序标记及其对应的编码
+type bom struct {
+	mark  []byte
+	width int // 编码单元字节数, 1 为 UTF-8
+	order binary.ByteOrder
+}
+
+// boms 已知的 BOM, UTF-32 LE 须在 UTF-16 LE 之前匹配
+var boms = []bom{
+	{[]byte{0xFF, 0xFE, 0x00, 0x00}, 4, binary.LittleEndian},
+	{[]byte{0x00, 0x00, 0xFE, 0xFF}, 4, binary.BigEndian},
+	{[]byte{0xEF, 0xBB, 0xBF}, 1, nil},
+	{[]byte{0xFF, 0xFE}, 2, binary.LittleEndian},
+	{[]byte{0xFE, 0xFF}, 2, binary.BigEndian},
+}
+
+func detectBOM(data []byte) (bom, bool) {
+	for _, b := range boms {
+		if bytes.HasPrefix(data, b.mark) {
+			return b, true
+		}
+	}
+	return bom{}, false
+}
+
+// HasBOM 判断文件是否以 UTF-8/UTF-16/UTF-32 BOM 开头
+func HasBOM(path string) (bool, error) {
+	head, err := ReadHead(path, 4)
+	if err != nil {
+		return false, err
+	}
+	_, ok := detectBOM(head)
+	return ok, nil
+}
+
+// GetContentsNoBOM (文本)读取文件内容, 去除开头的 UTF-8/UTF-16/UTF-32 BOM
+// 存在 UTF-16/UTF-32 BOM 时按对应编码解码为 UTF-8; 没有 BOM 时原样返回
+func GetContentsNoBOM(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	b, ok := detectBOM(data)
+	if !ok {
+		return string(data), nil
+	}
+	data = data[len(b.mark):]
+	switch b.width {
+	case 2:
+		units := make([]uint16, len(data)/2)
+		for i := range units {
+			units[i] = b.order.Uint16(data[2*i:])
+		}
+		return string(utf16.Decode(units)), nil
+	case 4:
+		var sb strings.Builder
+		for i := 0; i+4 <= len(data); i += 4 {
+			r := rune(b.order.Uint32(data[i:]))
+			if !utf8.ValidRune(r) {
+				r = utf8.RuneError
+			}
+			sb.WriteRune(r)
+		}
+		return sb.String(), nil
+	}
+	return string(data), nil
+}
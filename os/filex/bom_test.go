@@ -0,0 +1,42 @@
+package filex
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetContentsNoBOM(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	cases := map[string][]byte{
+		"utf8":    append([]byte{0xEF, 0xBB, 0xBF}, "a中"...),
+		"utf16le": {0xFF, 0xFE, 'a', 0x00, 0x2D, 0x4E},
+		"utf16be": {0xFE, 0xFF, 0x00, 'a', 0x4E, 0x2D},
+		"utf32le": {0xFF, 0xFE, 0x00, 0x00, 'a', 0, 0, 0, 0x2D, 0x4E, 0, 0},
+		"utf32be": {0x00, 0x00, 0xFE, 0xFF, 0, 0, 0, 'a', 0, 0, 0x4E, 0x2D},
+	}
+	for name, data := range cases {
+		p := filepath.Join(dir, name)
+		assert.Nil(PutBinContents(p, data))
+		ok, err := HasBOM(p)
+		assert.Nil(err)
+		assert.True(ok, name)
+		s, err := GetContentsNoBOM(p)
+		assert.Nil(err)
+		assert.Equal("a中", s, name)
+	}
+
+	plain := filepath.Join(dir, "plain")
+	assert.Nil(PutContents(plain, "a中"))
+	ok, err := HasBOM(plain)
+	assert.Nil(err)
+	assert.False(ok)
+	s, err := GetContentsNoBOM(plain)
+	assert.Nil(err)
+	assert.Equal("a中", s)
+
+	_, err = HasBOM(filepath.Join(dir, "missing"))
+	assert.NotNil(err)
+}
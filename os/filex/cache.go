@@ -0,0 +1,45 @@
+package filex
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"sync"
+)
+
+// CacheShardLen CacheFilePath 分片子目录名使用的哈希前缀长度, 为 0 时不分片
+var CacheShardLen = 2
+
+var (
+	cacheMu   sync.RWMutex
+	cacheRoot string
+)
+
+// SetCacheRoot 设置 CacheFilePath 使用的缓存根目录, 为空时恢复默认值(系统临时目录下的 gox-cache)
+func SetCacheRoot(dir string) {
+	cacheMu.Lock()
+	cacheRoot = dir
+	cacheMu.Unlock()
+}
+
+// CacheRoot 返回 CacheFilePath 使用的缓存根目录
+func CacheRoot() string {
+	cacheMu.RLock()
+	defer cacheMu.RUnlock()
+	if cacheRoot == "" {
+		return filepath.Join(TempDir(), "gox-cache")
+	}
+	return cacheRoot
+}
+
+// CacheFilePath 返回 key 对应的缓存文件路径, 相同的 key 总是得到相同的路径
+// 文件名为 key 的 SHA-256 十六进制值, 并按其前 CacheShardLen 个字符分片到子目录(如 ab/abcd...), 避免单个目录下文件过多
+// 只计算路径而不创建目录, 通过 PutContents 等函数写入时会自动创建
+func CacheFilePath(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:])
+	if n := CacheShardLen; n > 0 && n < len(name) {
+		return filepath.Join(CacheRoot(), name[:n], name)
+	}
+	return filepath.Join(CacheRoot(), name)
+}
@@ -0,0 +1,35 @@
+package filex
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheFilePath(t *testing.T) {
+	assert := assert.New(t)
+	root := t.TempDir()
+	SetCacheRoot(root)
+	defer SetCacheRoot("")
+
+	p := CacheFilePath("https://example.com/a.png")
+	assert.Equal(p, CacheFilePath("https://example.com/a.png"))
+	assert.NotEqual(p, CacheFilePath("https://example.com/b.png"))
+	name := Basename(p)
+	assert.Len(name, 64)
+	assert.Equal(filepath.Join(root, name[:2], name), p)
+
+	assert.False(Exists(Dir(p)))
+	assert.Nil(PutContents(p, "cached"))
+	assert.True(IsDir(filepath.Join(root, name[:2])))
+	assert.Equal("cached", GetContents(CacheFilePath("https://example.com/a.png")))
+
+	old := CacheShardLen
+	CacheShardLen = 0
+	defer func() { CacheShardLen = old }()
+	assert.Equal(filepath.Join(root, name), CacheFilePath("https://example.com/a.png"))
+
+	SetCacheRoot("")
+	assert.Equal(filepath.Join(TempDir(), "gox-cache"), CacheRoot())
+}
@@ -0,0 +1,588 @@
+package filex
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// copyBufferSize 复制文件时使用的缓冲区大小
+const copyBufferSize = 32 * 1024
+
+// CopyContext 可取消的文件复制
+// 每复制一块数据检查一次 ctx, ctx 取消或超时后返回 ctx.Err(), 并删除未完成的目标文件
+func CopyContext(ctx context.Context, src string, dst string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+	if err := mkdirParent(dst); err != nil {
+		return err
+	}
+	dstFile, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	_, err = io.CopyBuffer(writerOnly{dstFile}, &contextReader{ctx: ctx, r: srcFile}, make([]byte, copyBufferSize))
+	if err == nil {
+		err = dstFile.Sync()
+	}
+	if cerr := dstFile.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return nil
+}
+
+// contextReader 每次读取前检查 ctx 是否已取消
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+// writerOnly 隐藏 *os.File 的 ReadFrom, 使 io.CopyBuffer 按块复制
+type writerOnly struct {
+	io.Writer
+}
+
+const (
+	// progressMinBytes 进度回调最小字节间隔
+	progressMinBytes = 1024 * 1024
+	// progressMinInterval 进度回调最小时间间隔
+	progressMinInterval = 100 * time.Millisecond
+)
+
+// CopyProgress 带进度回调的文件复制
+// progress 参数为已复制字节数及文件总大小, 回调经过节流(每 1M 或每 100ms 最多一次),
+// 复制结束时总会以最终结果回调一次; 若复制期间源文件大小发生变化, 以实际复制的字节数作为 total
+func CopyProgress(src string, dst string, progress func(copied, total int64)) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+	fi, err := srcFile.Stat()
+	if err != nil {
+		return err
+	}
+	if err := mkdirParent(dst); err != nil {
+		return err
+	}
+	dstFile, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	pw := &progressWriter{w: dstFile, total: fi.Size(), fn: progress, last: time.Now()}
+	_, err = io.CopyBuffer(pw, srcFile, make([]byte, copyBufferSize))
+	if err == nil {
+		err = dstFile.Sync()
+	}
+	if cerr := dstFile.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	pw.finish()
+	return nil
+}
+
+// progressWriter 统计写入字节数并节流回调进度
+type progressWriter struct {
+	w        io.Writer
+	fn       func(copied, total int64)
+	copied   int64
+	total    int64
+	reported int64
+	last     time.Time
+	called   bool
+	lastSeen int64
+}
+
+func (pw *progressWriter) Write(p []byte) (int, error) {
+	n, err := pw.w.Write(p)
+	pw.copied += int64(n)
+	if pw.copied > pw.total {
+		pw.total = pw.copied
+	}
+	if pw.fn != nil && (pw.copied-pw.reported >= progressMinBytes || time.Since(pw.last) >= progressMinInterval) {
+		pw.report()
+	}
+	return n, err
+}
+
+func (pw *progressWriter) report() {
+	pw.reported = pw.copied
+	pw.last = time.Now()
+	pw.called = true
+	pw.lastSeen = pw.total
+	pw.fn(pw.copied, pw.total)
+}
+
+func (pw *progressWriter) finish() {
+	pw.total = pw.copied
+	if pw.fn != nil && (!pw.called || pw.reported != pw.copied || pw.lastSeen != pw.total) {
+		pw.report()
+	}
+}
+
+// CopyFsync 文件复制, 复制完成后同步目标文件及其所在目录到磁盘
+// 确保系统崩溃后目标文件内容及目录项均不会丢失
+func CopyFsync(src string, dst string) error {
+	if err := Copy(src, dst); err != nil {
+		return err
+	}
+	return syncDir(Dir(dst))
+}
+
+// syncDir 同步目录项到磁盘, Windows 不支持对目录执行同步, 直接忽略
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if cerr := d.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// SymlinkMode 复制目录时对符号链接的处理方式
+type SymlinkMode int
+
+const (
+	// SymlinkPreserve 重建符号链接(默认)
+	// 指向目录树内部的链接在目标目录树中指向对应的位置, 指向外部的链接仍指向原来的目标
+	SymlinkPreserve SymlinkMode = iota
+	// SymlinkFollow 跟随符号链接, 复制链接目标的内容, 指向目录的链接递归复制该目录
+	SymlinkFollow
+	// SymlinkSkip 忽略符号链接
+	SymlinkSkip
+)
+
+// CopyDirOptions 目录复制选项
+type CopyDirOptions struct {
+	// Symlinks 符号链接的处理方式, 默认为 SymlinkPreserve
+	Symlinks SymlinkMode
+	// NameFunc 将文件(非目录)相对于源目录的路径映射为相对于目标目录的路径, 返回空字符串时跳过该文件
+	// 映射结果超出目标目录时返回 ErrPathOutsideBase; 目录按原名创建
+	NameFunc func(relPath string) string
+}
+
+// CopyDir 递归复制目录, 保留文件及目录的权限和修改时间, 符号链接的处理方式见 CopyDirOptions
+// dst 为 src 本身或位于 src 之内时返回 ErrCopyIntoSelf
+func CopyDir(src string, dst string, opts ...CopyDirOptions) error {
+	c := &dirCopier{visiting: make(map[string]bool)}
+	if len(opts) > 0 {
+		c.opts = opts[0]
+	}
+	var err error
+	if c.srcRoot, err = filepath.Abs(src); err != nil {
+		return err
+	}
+	if c.dstRoot, err = filepath.Abs(dst); err != nil {
+		return err
+	}
+	// 解析符号链接后比较, 经由链接指向源目录内部的目标目录同样拒绝
+	s, err := resolvePath(c.srcRoot)
+	if err != nil {
+		return err
+	}
+	d, err := resolvePath(c.dstRoot)
+	if err != nil {
+		return err
+	}
+	if isWithin(s, d) {
+		return &os.PathError{Op: "copydir", Path: dst, Err: ErrCopyIntoSelf}
+	}
+	return c.copyDir(c.srcRoot, c.dstRoot)
+}
+
+// ErrCopyIntoSelf 目标目录为源目录本身或位于其中
+var ErrCopyIntoSelf = errors.New("cannot copy a directory into itself")
+
+// dirCopier CopyDir 的复制状态
+type dirCopier struct {
+	opts     CopyDirOptions
+	srcRoot  string
+	dstRoot  string
+	visiting map[string]bool // 正在复制的目录(已解析符号链接), 用于检测 SymlinkFollow 时的循环
+}
+
+func (c *dirCopier) copyDir(src, dst string) error {
+	real, err := filepath.EvalSymlinks(src)
+	if err != nil {
+		return err
+	}
+	if c.visiting[real] {
+		return &os.PathError{Op: "copydir", Path: src, Err: errors.New("symlink cycle")}
+	}
+	c.visiting[real] = true
+	defer delete(c.visiting, real)
+
+	type dirAttr struct {
+		path string
+		info os.FileInfo
+	}
+	var dirs []dirAttr
+	err = filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			// 先保证目录可写, 复制完成后再设置原始权限
+			if err := MkdirMode(target, info.Mode().Perm()|0700); err != nil {
+				return err
+			}
+			dirs = append(dirs, dirAttr{target, info})
+			return nil
+		}
+		if c.opts.NameFunc != nil {
+			// Follow 模式下 src 可能位于源目录之外, 以目标路径换算相对于根目录的路径
+			rel, err := filepath.Rel(c.dstRoot, target)
+			if err != nil {
+				return err
+			}
+			var ok bool
+			if target, ok, err = mapName(c.opts.NameFunc, c.dstRoot, rel); err != nil || !ok {
+				return err
+			}
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return c.copySymlink(p, target)
+		}
+		return copyEntry(p, target, info)
+	})
+	if err != nil {
+		return err
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := os.Chmod(dirs[i].path, dirs[i].info.Mode().Perm()); err != nil {
+			return err
+		}
+		if err := os.Chtimes(dirs[i].path, dirs[i].info.ModTime(), dirs[i].info.ModTime()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *dirCopier) copySymlink(src, dst string) error {
+	switch c.opts.Symlinks {
+	case SymlinkSkip:
+		return nil
+	case SymlinkFollow:
+		info, err := os.Stat(src)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return copyEntry(src, dst, info)
+		}
+		// filepath.WalkDir 不跟随作为根目录的符号链接, 需要先解析
+		real, err := filepath.EvalSymlinks(src)
+		if err != nil {
+			return err
+		}
+		return c.copyDir(real, dst)
+	}
+	link, err := os.Readlink(src)
+	if err != nil {
+		return err
+	}
+	target := link
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(src), link)
+	}
+	if isWithin(c.srcRoot, target) {
+		if filepath.IsAbs(link) {
+			rel, err := filepath.Rel(c.srcRoot, target)
+			if err != nil {
+				return err
+			}
+			link = filepath.Join(c.dstRoot, rel)
+		}
+	} else if !filepath.IsAbs(link) {
+		// 目录树外部的相对链接, 改写为从新位置指向原目标
+		if link, err = filepath.Rel(filepath.Dir(dst), target); err != nil {
+			return err
+		}
+	}
+	if err := mkdirParent(dst); err != nil {
+		return err
+	}
+	return os.Symlink(link, dst)
+}
+
+// mapName 通过 fn 将相对路径 rel 映射为 root 下的路径, fn 为 nil 时不映射; fn 返回空字符串时 ok 为 false
+func mapName(fn func(string) string, root, rel string) (p string, ok bool, err error) {
+	if fn == nil {
+		return filepath.Join(root, rel), true, nil
+	}
+	name := fn(rel)
+	if name == "" {
+		return "", false, nil
+	}
+	p, err = joinWithin(root, name)
+	if err != nil {
+		return "", false, err
+	}
+	return p, true, nil
+}
+
+// copyEntry 复制单个非目录文件, 保留权限及修改时间, 符号链接按原样重建, 其他特殊文件忽略
+func copyEntry(src, dst string, info os.FileInfo) error {
+	mode := info.Mode()
+	if mode&os.ModeSymlink != 0 {
+		link, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		if err := mkdirParent(dst); err != nil {
+			return err
+		}
+		return os.Symlink(link, dst)
+	}
+	if !mode.IsRegular() {
+		return nil
+	}
+	if err := Copy(src, dst); err != nil {
+		return err
+	}
+	if err := os.Chmod(dst, mode.Perm()); err != nil {
+		return err
+	}
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
+}
+
+// CopyTo 将 src 中的数据复制到 dst, 返回复制的字节数
+func CopyTo(dst io.Writer, src io.Reader) (int64, error) {
+	return io.CopyBuffer(dst, src, make([]byte, copyBufferSize))
+}
+
+// CopyFrom 将 src 中的数据写入 path 文件(截断已有内容), 自动创建文件所在目录, 写入完成后同步到磁盘
+// 返回写入的字节数, 可用于将 http.Response.Body 等数据流直接保存到文件
+func CopyFrom(path string, src io.Reader) (int64, error) {
+	if err := mkdirParent(path); err != nil {
+		return 0, err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	n, err := CopyTo(f, src)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return n, err
+}
+
+// sparseBlockSize CopySparse 检测全零数据块的大小
+const sparseBlockSize = 4096
+
+// CopySparse 复制文件, 全零的数据块不写入目标文件而是跳过(Seek), 使目标文件成为稀疏文件
+// 适用于虚拟机镜像等包含大量空洞的文件; 文件系统不支持稀疏文件时跳过的部分以 0 填充, 内容保持一致
+// 目标文件沿用源文件的权限, 失败时删除未完成的目标文件
+func CopySparse(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	fi, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	if err := mkdirParent(dst); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fi.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	err = copySparse(out, in)
+	if err == nil {
+		err = out.Sync()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return nil
+}
+
+func copySparse(out *os.File, in io.Reader) error {
+	buf := make([]byte, copyBufferSize)
+	var size int64
+	for {
+		n, err := io.ReadFull(in, buf)
+		for off := 0; off < n; off += sparseBlockSize {
+			block := buf[off:min(off+sparseBlockSize, n)]
+			if isZero(block) {
+				if _, err := out.Seek(int64(len(block)), io.SeekCurrent); err != nil {
+					return err
+				}
+			} else if _, err := out.Write(block); err != nil {
+				return err
+			}
+		}
+		size += int64(n)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	// 文件末尾为空洞时 Seek 不会改变文件大小, 需要显式设置
+	return out.Truncate(size)
+}
+
+func isZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ErrChecksumMismatch 复制后目标文件与源文件的校验和不一致
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// verifiedWriter 包装 CopyVerified 写入目标文件的 Writer, 测试时可替换以模拟写入损坏
+var verifiedWriter = func(w io.Writer) io.Writer { return w }
+
+// CopyVerified 复制文件并校验: 复制过程中计算源文件的 SHA-256, 复制完成后重新读取目标文件计算 SHA-256
+// 两者不一致时删除目标文件并返回 ErrChecksumMismatch
+func CopyVerified(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	h := sha256.New()
+	if err := writeStream(dst, 0666, func(w io.Writer) error {
+		_, err := io.CopyBuffer(verifiedWriter(w), io.TeeReader(in, h), make([]byte, copyBufferSize))
+		return err
+	}); err != nil {
+		return err
+	}
+	want := h.Sum(nil)
+	got, err := fileSHA256(dst)
+	if err != nil {
+		os.Remove(dst)
+		return err
+	}
+	if !bytes.Equal(want, got) {
+		os.Remove(dst)
+		return fmt.Errorf("copy %s: %w", dst, ErrChecksumMismatch)
+	}
+	return nil
+}
+
+func fileSHA256(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.CopyBuffer(h, f, make([]byte, copyBufferSize)); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// CopyPair 待复制的源文件与目标文件
+type CopyPair struct {
+	Src string
+	Dst string
+}
+
+// CopyPairError 单个文件复制失败的错误
+type CopyPairError struct {
+	Pair CopyPair
+	Err  error
+}
+
+func (e *CopyPairError) Error() string {
+	return "copy " + e.Pair.Src + " -> " + e.Pair.Dst + ": " + e.Err.Error()
+}
+
+func (e *CopyPairError) Unwrap() error {
+	return e.Err
+}
+
+// CopyAll 使用 workers 个并发协程批量复制文件, workers <= 0 时使用 GOMAXPROCS
+// 同时打开的文件数不超过 2*workers; 所有失败的复制以 *CopyPairError 形式合并为一个错误返回(errors.Join)
+func CopyAll(pairs []CopyPair, workers int) error {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(pairs) {
+		workers = len(pairs)
+	}
+	errs := make([]error, len(pairs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := Copy(pairs[i].Src, pairs[i].Dst); err != nil {
+					errs[i] = &CopyPairError{Pair: pairs[i], Err: err}
+				}
+			}
+		}()
+	}
+	for i := range pairs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return errors.Join(errs...)
+}
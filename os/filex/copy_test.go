@@ -0,0 +1,242 @@
+package filex
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// cancelAfterContext 在 Err 被调用 n 次后变为已取消
+type cancelAfterContext struct {
+	context.Context
+	n int32
+}
+
+func (c *cancelAfterContext) Err() error {
+	if atomic.AddInt32(&c.n, -1) < 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestCopyContext(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.bin")
+	assert.Nil(Create(src))
+	assert.Nil(Truncate(src, 64*1024*1024))
+
+	dst := filepath.Join(dir, "out", "dst.bin")
+	ctx := &cancelAfterContext{Context: context.Background(), n: 10}
+	start := time.Now()
+	err := CopyContext(ctx, src, dst)
+	assert.Equal(context.Canceled, err)
+	assert.True(time.Since(start) < time.Second)
+	assert.False(Exists(dst), "partial destination should be removed")
+
+	cctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(context.Canceled, CopyContext(cctx, src, dst))
+	assert.False(Exists(dst))
+
+	assert.Nil(CopyContext(context.Background(), src, dst))
+	assert.Equal(Size(src), Size(dst))
+}
+
+func TestCopyProgress(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.bin")
+	assert.Nil(Create(src))
+	assert.Nil(Truncate(src, 10*1024*1024+123))
+
+	var calls [][2]int64
+	err := CopyProgress(src, filepath.Join(dir, "dst.bin"), func(copied, total int64) {
+		calls = append(calls, [2]int64{copied, total})
+	})
+	assert.Nil(err)
+	assert.True(len(calls) >= 2)
+	assert.True(len(calls) <= 12, "callbacks should be throttled")
+	for i := 1; i < len(calls); i++ {
+		assert.True(calls[i][0] > calls[i-1][0], "progress should increase")
+	}
+	assert.Equal([2]int64{Size(src), Size(src)}, calls[len(calls)-1])
+
+	empty := filepath.Join(dir, "empty.bin")
+	assert.Nil(Create(empty))
+	calls = nil
+	assert.Nil(CopyProgress(empty, filepath.Join(dir, "empty2.bin"), func(copied, total int64) {
+		calls = append(calls, [2]int64{copied, total})
+	}))
+	assert.Equal([][2]int64{{0, 0}}, calls)
+}
+
+func TestCopyFsync(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.txt")
+	assert.Nil(PutContents(src, "durable"))
+	dst := filepath.Join(dir, "a", "b", "dst.txt")
+	assert.Nil(CopyFsync(src, dst))
+	assert.Equal("durable", GetContents(dst))
+	assert.NotNil(CopyFsync(filepath.Join(dir, "missing"), dst))
+}
+
+// failingWriter 写入 n 字节后返回错误
+type failingWriter struct {
+	n int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		k := w.n
+		w.n = 0
+		return k, errors.New("disk full")
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func TestCopyToFrom(t *testing.T) {
+	assert := assert.New(t)
+	content := strings.Repeat("stream ", 20000)
+
+	var buf bytes.Buffer
+	n, err := CopyTo(&buf, strings.NewReader(content))
+	assert.Nil(err)
+	assert.Equal(int64(len(content)), n)
+	assert.Equal(content, buf.String())
+
+	n, err = CopyTo(&failingWriter{n: 100}, strings.NewReader(content))
+	assert.EqualError(err, "disk full")
+	assert.Equal(int64(100), n)
+
+	p := filepath.Join(t.TempDir(), "a", "b.txt")
+	assert.Nil(PutContents(p, strings.Repeat("x", len(content)*2)))
+	n, err = CopyFrom(p, strings.NewReader(content))
+	assert.Nil(err)
+	assert.Equal(int64(len(content)), n)
+	assert.Equal(content, GetContents(p))
+}
+
+func TestCopyAll(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	var pairs []CopyPair
+	for i := 0; i < 50; i++ {
+		src := filepath.Join(dir, "src", fmt.Sprintf("%02d.txt", i))
+		assert.Nil(PutContents(src, fmt.Sprintf("file %d", i)))
+		pairs = append(pairs, CopyPair{Src: src, Dst: filepath.Join(dir, "dst", fmt.Sprintf("%02d.txt", i))})
+	}
+	assert.Nil(CopyAll(pairs, 8))
+	for i, p := range pairs {
+		assert.Equal(fmt.Sprintf("file %d", i), GetContents(p.Dst))
+	}
+
+	bad := append([]CopyPair{}, pairs[:5]...)
+	bad = append(bad, CopyPair{Src: filepath.Join(dir, "missing1"), Dst: filepath.Join(dir, "x1")})
+	bad = append(bad, CopyPair{Src: filepath.Join(dir, "missing2"), Dst: filepath.Join(dir, "x2")})
+	err := CopyAll(bad, 0)
+	assert.NotNil(err)
+	var failed []string
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var pe *CopyPairError
+		assert.True(errors.As(e, &pe))
+		assert.True(os.IsNotExist(pe.Err))
+		failed = append(failed, filepath.Base(pe.Pair.Src))
+	}
+	assert.Equal([]string{"missing1", "missing2"}, failed)
+	assert.True(strings.Contains(err.Error(), "missing1"))
+
+	assert.Nil(CopyAll(nil, 4))
+}
+
+// flipWriter 将写入的第一个字节取反, 模拟写入时数据损坏
+type flipWriter struct {
+	w       io.Writer
+	flipped bool
+}
+
+func (f *flipWriter) Write(p []byte) (int, error) {
+	if !f.flipped && len(p) > 0 {
+		q := append([]byte(nil), p...)
+		q[0] ^= 0xff
+		f.flipped = true
+		return f.w.Write(q)
+	}
+	return f.w.Write(p)
+}
+
+func TestCopyVerified(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	src, dst := filepath.Join(dir, "backup.db"), filepath.Join(dir, "out", "backup.db")
+	assert.Nil(PutContents(src, strings.Repeat("data", 20000)))
+
+	assert.Nil(CopyVerified(src, dst))
+	same, err := SameContent(src, dst)
+	assert.Nil(err)
+	assert.True(same)
+
+	old := verifiedWriter
+	verifiedWriter = func(w io.Writer) io.Writer { return &flipWriter{w: w} }
+	defer func() { verifiedWriter = old }()
+	bad := filepath.Join(dir, "bad.db")
+	err = CopyVerified(src, bad)
+	assert.True(errors.Is(err, ErrChecksumMismatch))
+	assert.False(Exists(bad), "corrupted destination should be removed")
+
+	assert.NotNil(CopyVerified(filepath.Join(dir, "missing"), bad))
+}
+
+func TestCopyDirIntoItself(t *testing.T) {
+	assert := assert.New(t)
+	src := filepath.Join(t.TempDir(), "src")
+	assert.Nil(PutContents(filepath.Join(src, "a.txt"), "a"))
+
+	for _, dst := range []string{src, filepath.Join(src, "backup"), filepath.Join(src, "sub", "..", "backup", "deep")} {
+		err := CopyDir(src, dst)
+		assert.True(errors.Is(err, ErrCopyIntoSelf), dst)
+	}
+	entries, err := os.ReadDir(src)
+	assert.Nil(err)
+	assert.Len(entries, 1, "nothing is created inside src")
+
+	assert.Nil(CopyDir(src, src+"-backup"), "a sibling with a common prefix is allowed")
+	assert.Equal("a", GetContents(filepath.Join(src+"-backup", "a.txt")))
+}
+
+func TestCopyDirNameFunc(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	src, dst := filepath.Join(dir, "template"), filepath.Join(dir, "project")
+	assert.Nil(PutContents(filepath.Join(src, "main.go.tmpl"), "package main"))
+	assert.Nil(PutContents(filepath.Join(src, "cmd", "app.go.tmpl"), "package cmd"))
+	assert.Nil(PutContents(filepath.Join(src, "README.md"), "readme"))
+	assert.Nil(PutContents(filepath.Join(src, ".skip"), ""))
+
+	err := CopyDir(src, dst, CopyDirOptions{NameFunc: func(rel string) string {
+		if Basename(rel) == ".skip" {
+			return ""
+		}
+		return strings.TrimSuffix(rel, ".tmpl")
+	}})
+	assert.Nil(err)
+	assert.Equal([]string{"README.md", "cmd", "main.go"}, ScanDir(dst))
+	assert.Equal("package cmd", GetContents(filepath.Join(dst, "cmd", "app.go")))
+
+	err = CopyDir(src, filepath.Join(dir, "bad"), CopyDirOptions{NameFunc: func(rel string) string {
+		return filepath.Join("..", rel)
+	}})
+	assert.True(errors.Is(err, ErrPathOutsideBase))
+}
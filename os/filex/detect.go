@@ -0,0 +1,201 @@
+package filex
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+)
+
+// sniffLen http.DetectContentType 最多使用的字节数
+const sniffLen = 512
+
+// defaultContentType 无法识别时使用的默认类型
+const defaultContentType = "application/octet-stream"
+
+// ContentType 根据文件开头最多 512 字节的内容识别 MIME 类型
+// 内容无法识别(application/octet-stream)或文件为空时, 根据扩展名识别, 仍无法识别时返回 application/octet-stream
+func ContentType(path string) (string, error) {
+	head, err := ReadHead(path, sniffLen)
+	if err != nil {
+		return "", err
+	}
+	ctype := defaultContentType
+	if len(head) > 0 {
+		ctype = http.DetectContentType(head)
+	}
+	if ctype == defaultContentType {
+		if t := mime.TypeByExtension(Ext(path)); t != "" {
+			return t, nil
+		}
+	}
+	return ctype, nil
+}
+
+// binarySniffLen IsBinary 检查的字节数, 与 git 相同
+const binarySniffLen = 8000
+
+// IsBinary 判断文件是否为二进制文件
+// 读取文件开头最多 8000 字节, 包含 NUL 字节或不可打印字符超过 30% 时视为二进制文件, 空文件视为文本文件
+func IsBinary(path string) (bool, error) {
+	head, err := ReadHead(path, binarySniffLen)
+	if err != nil {
+		return false, err
+	}
+	return isBinaryData(head), nil
+}
+
+func isBinaryData(head []byte) bool {
+	if len(head) == 0 {
+		return false
+	}
+	control := 0
+	for _, b := range head {
+		switch {
+		case b == 0:
+			return true
+		case b == '\t' || b == '\n' || b == '\r' || b == '\f' || b == '\b' || b == 0x1b:
+		case b < 0x20 || b == 0x7f:
+			control++
+		}
+	}
+	return control*10 > len(head)*3
+}
+
+// ReadHead 读取文件开头最多 n 字节, 文件不足 n 字节时返回全部内容而不报错
+// 可用于读取文件头魔数或作为 http.DetectContentType 的输入
+func ReadHead(path string, n int) ([]byte, error) {
+	if n < 0 {
+		return nil, errors.New("read head: negative length")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	buf := make([]byte, n)
+	m, err := io.ReadFull(f, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	return buf[:m], nil
+}
+
+// FileType 文件类别
+type FileType int
+
+const (
+	// TypeUnknown 无法识别
+	TypeUnknown FileType = iota
+	// TypeImage 图片
+	TypeImage
+	// TypeVideo 视频
+	TypeVideo
+	// TypeAudio 音频
+	TypeAudio
+	// TypeArchive 压缩包/归档文件
+	TypeArchive
+	// TypePDF PDF 文档
+	TypePDF
+	// TypeText 文本文件
+	TypeText
+)
+
+func (t FileType) String() string {
+	switch t {
+	case TypeImage:
+		return "image"
+	case TypeVideo:
+		return "video"
+	case TypeAudio:
+		return "audio"
+	case TypeArchive:
+		return "archive"
+	case TypePDF:
+		return "pdf"
+	case TypeText:
+		return "text"
+	}
+	return "unknown"
+}
+
+// signature 文件头魔数, container 非空时文件还须以 container 开头(如 RIFF 容器)
+type signature struct {
+	offset    int
+	magic     string
+	container string
+	typ       FileType
+}
+
+// signatures 已知的文件头魔数, 按顺序匹配
+var signatures = []signature{
+	{0, "\x89PNG\r\n\x1a\n", "", TypeImage},
+	{0, "\xff\xd8\xff", "", TypeImage},
+	{0, "GIF87a", "", TypeImage},
+	{0, "GIF89a", "", TypeImage},
+	{0, "II*\x00", "", TypeImage},
+	{0, "MM\x00*", "", TypeImage},
+	{0, "\x00\x00\x01\x00", "", TypeImage},
+	{8, "WEBP", "RIFF", TypeImage},
+
+	{8, "AVI ", "RIFF", TypeVideo},
+	{0, "\x1a\x45\xdf\xa3", "", TypeVideo},
+	{0, "FLV\x01", "", TypeVideo},
+	{0, "\x00\x00\x01\xba", "", TypeVideo},
+	{4, "ftypM4A ", "", TypeAudio},
+	{4, "ftyp", "", TypeVideo},
+
+	{8, "WAVE", "RIFF", TypeAudio},
+	{0, "ID3", "", TypeAudio},
+	{0, "\xff\xfb", "", TypeAudio},
+	{0, "\xff\xf3", "", TypeAudio},
+	{0, "\xff\xf2", "", TypeAudio},
+	{0, "fLaC", "", TypeAudio},
+	{0, "OggS", "", TypeAudio},
+	{0, "MThd", "", TypeAudio},
+
+	{0, "PK\x03\x04", "", TypeArchive},
+	{0, "PK\x05\x06", "", TypeArchive},
+	{0, "\x1f\x8b", "", TypeArchive},
+	{0, "BZh", "", TypeArchive},
+	{0, "\xfd7zXZ\x00", "", TypeArchive},
+	{0, "7z\xbc\xaf\x27\x1c", "", TypeArchive},
+	{0, "Rar!\x1a\x07", "", TypeArchive},
+	{0, "\x28\xb5\x2f\xfd", "", TypeArchive},
+	{257, "ustar", "", TypeArchive},
+
+	{0, "%PDF-", "", TypePDF},
+}
+
+func (s signature) match(head []byte) bool {
+	end := s.offset + len(s.magic)
+	return end <= len(head) && string(head[s.offset:end]) == s.magic &&
+		bytes.HasPrefix(head, []byte(s.container))
+}
+
+// DetectType 根据文件开头最多 512 字节的魔数识别文件类别
+// 未匹配任何魔数时, 以 BOM 开头或不像二进制内容(规则同 IsBinary)的文件视为 TypeText; 空文件返回 TypeUnknown
+func DetectType(path string) (FileType, error) {
+	head, err := ReadHead(path, sniffLen)
+	if err != nil {
+		return TypeUnknown, err
+	}
+	return detectType(head), nil
+}
+
+func detectType(head []byte) FileType {
+	if len(head) == 0 {
+		return TypeUnknown
+	}
+	for _, s := range signatures {
+		if s.match(head) {
+			return s.typ
+		}
+	}
+	if _, ok := detectBOM(head); ok || !isBinaryData(head) {
+		return TypeText
+	}
+	return TypeUnknown
+}
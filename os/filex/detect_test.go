@@ -0,0 +1,141 @@
+package filex
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pngHeader PNG 文件头及 IHDR 块开头
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00")
+
+func TestContentType(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+
+	png := filepath.Join(dir, "image.png")
+	assert.Nil(PutBinContents(png, pngHeader))
+	ctype, err := ContentType(png)
+	assert.Nil(err)
+	assert.Equal("image/png", ctype)
+
+	txt := filepath.Join(dir, "notes.txt")
+	assert.Nil(PutContents(txt, "hello world\n"))
+	ctype, err = ContentType(txt)
+	assert.Nil(err)
+	assert.Equal("text/plain; charset=utf-8", ctype)
+
+	// 扩展名与内容不符时以内容为准
+	fake := filepath.Join(dir, "photo.jpg")
+	assert.Nil(PutBinContents(fake, pngHeader))
+	ctype, err = ContentType(fake)
+	assert.Nil(err)
+	assert.Equal("image/png", ctype)
+
+	// 无法识别内容时使用扩展名
+	pdf := filepath.Join(dir, "doc.pdf")
+	assert.Nil(PutBinContents(pdf, []byte{0x00, 0x01, 0x02, 0x03}))
+	ctype, err = ContentType(pdf)
+	assert.Nil(err)
+	assert.Equal("application/pdf", ctype)
+
+	empty := filepath.Join(dir, "empty.unknownext")
+	assert.Nil(Create(empty))
+	ctype, err = ContentType(empty)
+	assert.Nil(err)
+	assert.Equal("application/octet-stream", ctype)
+
+	_, err = ContentType(filepath.Join(dir, "missing"))
+	assert.NotNil(err)
+}
+
+func TestIsBinary(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	cases := map[string]struct {
+		content []byte
+		binary  bool
+	}{
+		"utf8.txt":  {[]byte("纯文本内容\twith tabs\r\nand lines\n"), false},
+		"nul.txt":   {[]byte("text\x00more text"), true},
+		"image.png": {pngHeader, true},
+		"ctrl.bin":  {[]byte("\x01\x02\x03\x04abc"), true},
+		"empty.txt": {nil, false},
+	}
+	for name, c := range cases {
+		p := filepath.Join(dir, name)
+		assert.Nil(PutBinContents(p, c.content))
+		binary, err := IsBinary(p)
+		assert.Nil(err)
+		assert.Equal(c.binary, binary, name)
+	}
+	_, err := IsBinary(filepath.Join(dir, "missing"))
+	assert.NotNil(err)
+}
+
+func TestReadHead(t *testing.T) {
+	assert := assert.New(t)
+	p := filepath.Join(t.TempDir(), "f.bin")
+	assert.Nil(PutContents(p, "0123456789"))
+
+	for n, want := range map[int]string{0: "", 4: "0123", 10: "0123456789", 20: "0123456789"} {
+		head, err := ReadHead(p, n)
+		assert.Nil(err)
+		assert.Equal(want, string(head), "n=%d", n)
+	}
+	_, err := ReadHead(p, -1)
+	assert.NotNil(err)
+	_, err = ReadHead(filepath.Join(t.TempDir(), "missing"), 4)
+	assert.NotNil(err)
+}
+
+func TestDetectType(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+
+	zipPath := filepath.Join(dir, "bundle.dat")
+	f, err := os.Create(zipPath)
+	assert.Nil(err)
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("a.txt")
+	assert.Nil(err)
+	_, err = w.Write([]byte("hello"))
+	assert.Nil(err)
+	assert.Nil(zw.Close())
+	assert.Nil(f.Close())
+
+	files := map[string][]byte{
+		"image.bin": pngHeader,
+		"doc":       []byte("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n1 0 obj\n"),
+		"notes.png": []byte("plain text\nwith a misleading extension\n"),
+		"utf16.txt": {0xFF, 0xFE, 'h', 0, 'i', 0},
+		"sound.wav": []byte("RIFF\x24\x00\x00\x00WAVEfmt "),
+		"random":    {0x00, 0x01, 0x02, 0x03, 0xfe, 0x10},
+		"empty":     {},
+	}
+	for name, data := range files {
+		assert.Nil(PutBinContents(filepath.Join(dir, name), data))
+	}
+
+	for name, want := range map[string]FileType{
+		"image.bin":  TypeImage,
+		"bundle.dat": TypeArchive,
+		"doc":        TypePDF,
+		"notes.png":  TypeText,
+		"utf16.txt":  TypeText,
+		"sound.wav":  TypeAudio,
+		"random":     TypeUnknown,
+		"empty":      TypeUnknown,
+	} {
+		typ, err := DetectType(filepath.Join(dir, name))
+		assert.Nil(err)
+		assert.Equal(want, typ, name)
+	}
+	assert.Equal("archive", TypeArchive.String())
+
+	_, err = DetectType(filepath.Join(dir, "missing"))
+	assert.True(os.IsNotExist(err))
+}
@@ -0,0 +1,15 @@
+package filex
+
+// DiskUsage 获取 path 所在文件系统的总空间, 可用空间(非特权用户可用)及已用空间, 单位 bytes
+func DiskUsage(path string) (total, free, used uint64, err error) {
+	return diskUsage(path)
+}
+
+// HasFreeSpace 判断 path 所在文件系统的可用空间是否不少于 need 字节
+func HasFreeSpace(path string, need uint64) (bool, error) {
+	_, free, _, err := DiskUsage(path)
+	if err != nil {
+		return false, err
+	}
+	return free >= need, nil
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package filex
+
+import "errors"
+
+func diskUsage(path string) (total, free, used uint64, err error) {
+	return 0, 0, 0, errors.New("disk usage is not supported on this platform")
+}
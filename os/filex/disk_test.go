@@ -0,0 +1,27 @@
+package filex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiskUsage(t *testing.T) {
+	assert := assert.New(t)
+	total, free, used, err := DiskUsage(TempDir())
+	assert.Nil(err)
+	assert.True(total > 0)
+	assert.True(free > 0)
+	assert.True(total >= free)
+	assert.True(total >= used)
+
+	ok, err := HasFreeSpace(TempDir(), 1)
+	assert.Nil(err)
+	assert.True(ok)
+	ok, err = HasFreeSpace(TempDir(), total+1)
+	assert.Nil(err)
+	assert.False(ok)
+
+	_, _, _, err = DiskUsage("/definitely/missing/path")
+	assert.NotNil(err)
+}
@@ -0,0 +1,17 @@
+//go:build linux || darwin || freebsd
+
+package filex
+
+import "syscall"
+
+func diskUsage(path string) (total, free, used uint64, err error) {
+	var st syscall.Statfs_t
+	if err = syscall.Statfs(path, &st); err != nil {
+		return 0, 0, 0, err
+	}
+	bsize := uint64(st.Bsize)
+	total = uint64(st.Blocks) * bsize
+	free = uint64(st.Bavail) * bsize
+	used = (uint64(st.Blocks) - uint64(st.Bfree)) * bsize
+	return total, free, used, nil
+}
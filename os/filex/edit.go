@@ -0,0 +1,132 @@
+package filex
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"regexp"
+)
+
+// EditOptions Edit 选项
+type EditOptions struct {
+	// Lock 读写期间持有 path 的互斥锁(见 Lock), 用于多个进程/协程同时修改同一文件
+	Lock bool
+}
+
+// Edit 读取文件当前内容(文件不存在时为空)交给 fn 处理, 并将 fn 返回的内容原子写回
+// fn 返回错误时不会修改文件, 该错误原样返回
+func Edit(path string, fn func(old []byte) ([]byte, error), opts ...EditOptions) error {
+	var opt EditOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	edit := func() error {
+		old, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		data, err := fn(old)
+		if err != nil {
+			return err
+		}
+		return putContentsAtomic(path, data)
+	}
+	if opt.Lock {
+		return WithLock(path, edit)
+	}
+	return edit()
+}
+
+// ReplaceInFile 将文件中的 old 替换为 new, all 为 false 时只替换第一处, 返回替换次数
+// 通过临时文件原子写回, 没有匹配时不会重写文件
+func ReplaceInFile(path, old, new string, all bool) (int, error) {
+	if old == "" {
+		return 0, errors.New("replace: empty search string")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	n := bytes.Count(data, []byte(old))
+	if n == 0 {
+		return 0, nil
+	}
+	if !all {
+		n = 1
+	}
+	out := bytes.Replace(data, []byte(old), []byte(new), n)
+	if err := putContentsAtomic(path, out); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// ReplaceInFileRegexp 将文件中所有匹配 re 的内容替换为 repl(支持 $1 等分组引用), 返回替换次数
+// 通过临时文件原子写回, 没有匹配时不会重写文件
+func ReplaceInFileRegexp(path string, re *regexp.Regexp, repl string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	n := len(re.FindAllIndex(data, -1))
+	if n == 0 {
+		return 0, nil
+	}
+	out := re.ReplaceAll(data, []byte(repl))
+	if err := putContentsAtomic(path, out); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// PrependContents (文本)在文件开头插入内容, 文件不存在时等同于 PutContents
+// 先写入新内容再流式复制原有内容到临时文件, 最后原子替换原文件
+func PrependContents(path string, content string) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return PutContents(path, content)
+	}
+	return rewriteFile(path, func(r *bufio.Reader, w io.Writer) error {
+		if _, err := io.WriteString(w, content); err != nil {
+			return err
+		}
+		_, err := CopyTo(w, r)
+		return err
+	})
+}
+
+// LineEnding 换行符风格
+type LineEnding string
+
+const (
+	// LF Unix 风格换行符 \n
+	LF LineEnding = "\n"
+	// CRLF Windows 风格换行符 \r\n
+	CRLF LineEnding = "\r\n"
+	// CR 经典 Mac 风格换行符 \r
+	CR LineEnding = "\r"
+)
+
+// NormalizeLineEndings 将文件中的所有换行符(\r\n, \r, \n, 可混用)统一转换为 style
+// 通过临时文件原子写回, 内容已符合 style 时不会重写文件; 不会在末尾添加原本没有的换行符
+func NormalizeLineEndings(path string, style LineEnding) error {
+	switch style {
+	case LF, CRLF, CR:
+	default:
+		return errors.New("normalize line endings: unknown style")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	out := bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	out = bytes.ReplaceAll(out, []byte("\r"), []byte("\n"))
+	if style != LF {
+		out = bytes.ReplaceAll(out, []byte("\n"), []byte(style))
+	}
+	if bytes.Equal(out, data) {
+		return nil
+	}
+	return putContentsAtomic(path, out)
+}
@@ -0,0 +1,157 @@
+package filex
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEdit(t *testing.T) {
+	assert := assert.New(t)
+	path := filepath.Join(t.TempDir(), "conf", "counter")
+	incr := func(old []byte) ([]byte, error) {
+		n := 0
+		if len(old) > 0 {
+			var err error
+			if n, err = strconv.Atoi(string(old)); err != nil {
+				return nil, err
+			}
+		}
+		return []byte(strconv.Itoa(n + 1)), nil
+	}
+
+	assert.Nil(Edit(path, incr))
+	assert.Equal("1", GetContents(path))
+
+	const workers, each = 8, 25
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < each; j++ {
+				assert.Nil(Edit(path, incr, EditOptions{Lock: true}))
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal("201", GetContents(path))
+
+	info, err := os.Stat(path)
+	assert.Nil(err)
+	errAbort := errors.New("abort")
+	err = Edit(path, func(old []byte) ([]byte, error) {
+		return []byte("partial"), errAbort
+	})
+	assert.Equal(errAbort, err)
+	assert.Equal("201", GetContents(path))
+	after, err := os.Stat(path)
+	assert.Nil(err)
+	assert.True(os.SameFile(info, after), "file is not replaced")
+
+	missing := filepath.Join(t.TempDir(), "missing")
+	assert.Equal(errAbort, Edit(missing, func([]byte) ([]byte, error) { return nil, errAbort }))
+	assert.False(Exists(missing))
+}
+
+func TestReplaceInFile(t *testing.T) {
+	assert := assert.New(t)
+	p := filepath.Join(t.TempDir(), "app.conf")
+	assert.Nil(PutContents(p, "host=a\nport=1\nhost=a\n"))
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	assert.Nil(os.Chtimes(p, past, past))
+
+	n, err := ReplaceInFile(p, "missing", "x", true)
+	assert.Nil(err)
+	assert.Equal(0, n)
+	info, _ := os.Stat(p)
+	assert.True(past.Equal(info.ModTime()), "file must not be rewritten without matches")
+
+	n, err = ReplaceInFile(p, "host=a", "host=b", false)
+	assert.Nil(err)
+	assert.Equal(1, n)
+	assert.Equal("host=b\nport=1\nhost=a\n", GetContents(p))
+
+	n, err = ReplaceInFile(p, "host=", "server=", true)
+	assert.Nil(err)
+	assert.Equal(2, n)
+	assert.Equal("server=b\nport=1\nserver=a\n", GetContents(p))
+
+	_, err = ReplaceInFile(p, "", "x", true)
+	assert.NotNil(err)
+}
+
+func TestReplaceInFileRegexp(t *testing.T) {
+	assert := assert.New(t)
+	p := filepath.Join(t.TempDir(), "doc.txt")
+	assert.Nil(PutContents(p, "begin\nsecret 1\nsecret 2\nend\nkeep"))
+
+	n, err := ReplaceInFileRegexp(p, regexp.MustCompile(`nothing`), "")
+	assert.Nil(err)
+	assert.Equal(0, n)
+
+	n, err = ReplaceInFileRegexp(p, regexp.MustCompile(`(?s)begin\n.*?end\n`), "[removed]\n")
+	assert.Nil(err)
+	assert.Equal(1, n)
+	assert.Equal("[removed]\nkeep", GetContents(p))
+
+	assert.Nil(PutContents(p, "a=1\nb=2\n"))
+	n, err = ReplaceInFileRegexp(p, regexp.MustCompile(`(?m)^(\w+)=(\d+)$`), "$2=$1")
+	assert.Nil(err)
+	assert.Equal(2, n)
+	assert.Equal("1=a\n2=b\n", GetContents(p))
+	entries, _ := os.ReadDir(filepath.Dir(p))
+	assert.Len(entries, 1, "no temp files left behind")
+}
+
+func TestPrependContents(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+
+	p := filepath.Join(dir, "new", "CHANGELOG")
+	assert.Nil(PrependContents(p, "v1\n"))
+	assert.Equal("v1\n", GetContents(p))
+
+	big := strings.Repeat("old line\n", 200000)
+	assert.Nil(PutContents(p, big))
+	assert.Nil(PrependContents(p, "// License header\n"))
+	content := GetContents(p)
+	assert.Equal(len(big)+18, len(content))
+	assert.True(strings.HasPrefix(content, "// License header\nold line\n"))
+	assert.True(strings.HasSuffix(content, big))
+}
+
+func TestNormalizeLineEndings(t *testing.T) {
+	assert := assert.New(t)
+	p := filepath.Join(t.TempDir(), "f.txt")
+
+	assert.Nil(PutContents(p, "a\r\nb\r\nc"))
+	assert.Nil(NormalizeLineEndings(p, LF))
+	assert.Equal("a\nb\nc", GetContents(p))
+
+	assert.Nil(NormalizeLineEndings(p, CRLF))
+	assert.Equal("a\r\nb\r\nc", GetContents(p))
+
+	assert.Nil(PutContents(p, "a\nb\r\nc\rd\n"))
+	assert.Nil(NormalizeLineEndings(p, CR))
+	assert.Equal("a\rb\rc\rd\r", GetContents(p))
+
+	// 已是目标风格时不重写文件
+	assert.Nil(PutContents(p, "a\nb\n"))
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	assert.Nil(os.Chtimes(p, old, old))
+	assert.Nil(NormalizeLineEndings(p, LF))
+	info, err := os.Stat(p)
+	assert.Nil(err)
+	assert.True(info.ModTime().Equal(old))
+
+	assert.NotNil(NormalizeLineEndings(p, LineEnding("\t")))
+}
@@ -0,0 +1,165 @@
+package filex
+
+import (
+	"bufio"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/encoding"
+)
+
+// ReadJSON 读取 JSON 文件并解码到 v, 解码失败时返回包含文件路径的错误
+func ReadJSON(path string, v interface{}) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := json.NewDecoder(bufio.NewReader(f)).Decode(v); err != nil {
+		return fmt.Errorf("decode json %s: %w", path, err)
+	}
+	return nil
+}
+
+// WriteJSON 将 v 编码为 JSON 原子写入文件, indent 为 true 时使用两个空格缩进格式化输出
+func WriteJSON(path string, v interface{}, indent bool) error {
+	return writeFileAtomic(path, 0666, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		if indent {
+			enc.SetIndent("", "  ")
+		}
+		return enc.Encode(v)
+	})
+}
+
+// ReadGob 读取 gob 文件并解码到 v, 解码失败时返回包含文件路径的错误
+// 文件不存在时返回 os.IsNotExist 可识别的错误; 文件为空时返回的错误满足 errors.Is(err, io.EOF)
+func ReadGob(path string, v interface{}) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := gob.NewDecoder(bufio.NewReader(f)).Decode(v); err != nil {
+		return fmt.Errorf("decode gob %s: %w", path, err)
+	}
+	return nil
+}
+
+// WriteGob 将 v 编码为 gob 原子写入文件
+func WriteGob(path string, v interface{}) error {
+	return writeFileAtomic(path, 0666, func(w io.Writer) error {
+		return gob.NewEncoder(w).Encode(v)
+	})
+}
+
+// GetContentsEncoding 以指定编码读取文本文件, 返回 UTF-8 字符串, 如 GetContentsEncoding(path, simplifiedchinese.GBK)
+func GetContentsEncoding(path string, enc encoding.Encoding) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(enc.NewDecoder().Reader(bufio.NewReader(f)))
+	if err != nil {
+		return "", fmt.Errorf("decode %s: %w", path, err)
+	}
+	return string(data), nil
+}
+
+// PutContentsEncoding 将 UTF-8 文本转换为指定编码后原子写入文件
+// 内容包含目标编码无法表示的字符时返回错误, 原文件保持不变
+func PutContentsEncoding(path string, content string, enc encoding.Encoding) error {
+	return writeFileAtomic(path, 0666, func(w io.Writer) error {
+		ew := enc.NewEncoder().Writer(w)
+		if _, err := io.WriteString(ew, content); err != nil {
+			return fmt.Errorf("encode %s: %w", path, err)
+		}
+		// 编码 Writer 需要 Close 才会写出缓冲的内容
+		if c, ok := ew.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				return fmt.Errorf("encode %s: %w", path, err)
+			}
+		}
+		return nil
+	})
+}
+
+// ReadEnvFile 读取 .env 格式的文件, 每行为 KEY=VALUE, 返回键值对
+// 忽略空行及 # 开头的注释行, 键及值两端的空白被去除, 允许 export 前缀; 值只在第一个 = 处分隔, 可以包含 =
+// 双引号包围的值支持 Go 字符串转义(如 \n, \"), 单引号包围的值按原样去除引号
+func ReadEnvFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	env := make(map[string]string)
+	n := 0
+	var perr error
+	err = eachLine(f, func(line string) bool {
+		n++
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			return true
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			perr = fmt.Errorf("parse env %s:%d: invalid line", path, n)
+			return false
+		}
+		env[key] = unquoteEnv(strings.TrimSpace(value))
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if perr != nil {
+		return nil, perr
+	}
+	return env, nil
+}
+
+func unquoteEnv(v string) string {
+	if len(v) >= 2 {
+		switch {
+		case v[0] == '"' && v[len(v)-1] == '"':
+			if s, err := strconv.Unquote(v); err == nil {
+				return s
+			}
+			return v[1 : len(v)-1]
+		case v[0] == '\'' && v[len(v)-1] == '\'':
+			return v[1 : len(v)-1]
+		}
+	}
+	return v
+}
+
+// WriteEnvFile 将键值对以 KEY=VALUE 格式原子写入 .env 文件, 按键排序
+// 值为空或包含空白, 引号, #, \ 等字符时以双引号包围并转义, 可由 ReadEnvFile 原样读回
+func WriteEnvFile(path string, kv map[string]string) error {
+	keys := make([]string, 0, len(kv))
+	for k := range kv {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return writeFileAtomic(path, 0666, func(w io.Writer) error {
+		for _, k := range keys {
+			v := kv[k]
+			if v == "" || strings.ContainsAny(v, " \t\r\n\"'#\\") {
+				v = strconv.Quote(v)
+			}
+			if _, err := io.WriteString(w, k+"="+v+"\n"); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,170 @@
+package filex
+
+import (
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+type testConfig struct {
+	Name    string            `json:"name"`
+	Port    int               `json:"port"`
+	Tags    []string          `json:"tags"`
+	Options map[string]string `json:"options"`
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	p := filepath.Join(t.TempDir(), "conf", "app.json")
+	in := testConfig{Name: "gox", Port: 8080, Tags: []string{"a", "b"}, Options: map[string]string{"k": "v"}}
+
+	assert.Nil(WriteJSON(p, in, false))
+	assert.Equal(`{"name":"gox","port":8080,"tags":["a","b"],"options":{"k":"v"}}`+"\n", GetContents(p))
+
+	assert.Nil(WriteJSON(p, in, true))
+	assert.True(strings.Contains(GetContents(p), "\n  \"port\": 8080,\n"))
+
+	var out testConfig
+	assert.Nil(ReadJSON(p, &out))
+	assert.Equal(in, out)
+
+	// 编码失败时原文件保持不变
+	assert.NotNil(WriteJSON(p, math.Inf(1), false))
+	out = testConfig{}
+	assert.Nil(ReadJSON(p, &out))
+	assert.Equal(in, out)
+	entries, _ := os.ReadDir(filepath.Dir(p))
+	assert.Len(entries, 1)
+
+	assert.Nil(PutContents(p, "{broken"))
+	err := ReadJSON(p, &out)
+	assert.NotNil(err)
+	assert.True(strings.Contains(err.Error(), p))
+}
+
+func TestContentsEncoding(t *testing.T) {
+	assert := assert.New(t)
+	path := filepath.Join(t.TempDir(), "gbk.txt")
+
+	const text = "中文 abc 编码\n简体字：发展、计算机，标点符号。\n"
+	assert.Nil(PutContentsEncoding(path, text, simplifiedchinese.GBK))
+	data := GetBinContents(path)
+	assert.Equal([]byte{0xD6, 0xD0, 0xCE, 0xC4, ' ', 'a', 'b', 'c', ' ', 0xB1, 0xE0, 0xC2, 0xEB, '\n'}, data[:14])
+	want, err := simplifiedchinese.GBK.NewEncoder().String(text)
+	assert.Nil(err)
+	assert.Equal(want, string(data))
+	assert.NotEqual(text, GetContents(path), "GBK bytes are not valid UTF-8")
+
+	s, err := GetContentsEncoding(path, simplifiedchinese.GBK)
+	assert.Nil(err)
+	assert.Equal(text, s)
+
+	// 编码失败时不改动原文件
+	assert.NotNil(PutContentsEncoding(path, "表情 😀", simplifiedchinese.GBK))
+	s, err = GetContentsEncoding(path, simplifiedchinese.GBK)
+	assert.Nil(err)
+	assert.Equal(text, s)
+
+	_, err = GetContentsEncoding(filepath.Join(t.TempDir(), "missing"), simplifiedchinese.GBK)
+	assert.True(os.IsNotExist(err))
+}
+
+func TestContentsEncodingLatin1(t *testing.T) {
+	assert := assert.New(t)
+	path := filepath.Join(t.TempDir(), "latin1.txt")
+
+	const text = "café, naïve, Ñandú ±½\n"
+	assert.Nil(PutContentsEncoding(path, text, charmap.ISO8859_1))
+	data := GetBinContents(path)
+	assert.Equal(len([]rune(text)), len(data), "one byte per character")
+	assert.Equal([]byte("caf\xe9"), data[:4])
+
+	s, err := GetContentsEncoding(path, charmap.ISO8859_1)
+	assert.Nil(err)
+	assert.Equal(text, s)
+
+	assert.NotNil(PutContentsEncoding(path, "中文", charmap.ISO8859_1))
+	assert.Equal(data, GetBinContents(path))
+}
+
+type testCache struct {
+	Version int
+	Items   [][]string
+	Index   map[string][]int
+}
+
+func TestGobRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	p := filepath.Join(t.TempDir(), "cache", "data.gob")
+	in := testCache{
+		Version: 3,
+		Items:   [][]string{{"a", "b"}, {}, {"c"}},
+		Index:   map[string][]int{"a": {0}, "c": {2, 5}},
+	}
+	assert.Nil(WriteGob(p, in))
+	var out testCache
+	assert.Nil(ReadGob(p, &out))
+	assert.Equal(in.Version, out.Version)
+	assert.Equal(in.Index, out.Index)
+	assert.Equal([]string{"a", "b"}, out.Items[0])
+	assert.Equal([]string{"c"}, out.Items[2])
+
+	err := ReadGob(filepath.Join(t.TempDir(), "missing.gob"), &out)
+	assert.True(os.IsNotExist(err))
+
+	assert.Nil(PutContents(p, ""))
+	err = ReadGob(p, &out)
+	assert.True(errors.Is(err, io.EOF))
+	assert.Contains(err.Error(), p)
+
+	assert.Nil(PutContents(p, "garbage"))
+	err = ReadGob(p, &out)
+	assert.NotNil(err)
+	assert.False(errors.Is(err, io.EOF))
+}
+
+func TestEnvFile(t *testing.T) {
+	assert := assert.New(t)
+	p := filepath.Join(t.TempDir(), ".env")
+	assert.Nil(PutContents(p, `# database
+DB_HOST = localhost
+export DB_USER=admin
+
+DB_PASS="p@ss word"
+DSN=postgres://u:p@h/db?sslmode=disable&a=b
+GREETING='hello "world"'
+  # indented comment
+EMPTY=
+MULTI="line1\nline2"
+`))
+	env, err := ReadEnvFile(p)
+	assert.Nil(err)
+	assert.Equal(map[string]string{
+		"DB_HOST":  "localhost",
+		"DB_USER":  "admin",
+		"DB_PASS":  "p@ss word",
+		"DSN":      "postgres://u:p@h/db?sslmode=disable&a=b",
+		"GREETING": `hello "world"`,
+		"EMPTY":    "",
+		"MULTI":    "line1\nline2",
+	}, env)
+
+	q := filepath.Join(t.TempDir(), "out.env")
+	assert.Nil(WriteEnvFile(q, env))
+	assert.True(strings.HasPrefix(GetContents(q), "DB_HOST=localhost\nDB_PASS=\"p@ss word\"\n"))
+	back, err := ReadEnvFile(q)
+	assert.Nil(err)
+	assert.Equal(env, back)
+
+	assert.Nil(PutContents(p, "OK=1\nnot a pair\n"))
+	_, err = ReadEnvFile(p)
+	assert.EqualError(err, "parse env "+p+":2: invalid line")
+}
@@ -0,0 +1,10 @@
+//go:build !unix && !windows
+
+package filex
+
+import "os"
+
+// isExecutable 无 access(2) 的平台仅检查权限位
+func isExecutable(path string, info os.FileInfo) bool {
+	return info.Mode().Perm()&0111 != 0
+}
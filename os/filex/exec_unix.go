@@ -0,0 +1,18 @@
+//go:build unix
+
+package filex
+
+import (
+	"os"
+	"syscall"
+)
+
+// accessX 对应 access(2) 的 X_OK
+const accessX = 0x1
+
+func isExecutable(path string, info os.FileInfo) bool {
+	if info.Mode().Perm()&0111 == 0 {
+		return false
+	}
+	return syscall.Access(path, accessX) == nil
+}
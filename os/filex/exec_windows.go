@@ -0,0 +1,24 @@
+package filex
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func isExecutable(path string, info os.FileInfo) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == "" {
+		return false
+	}
+	pathext := os.Getenv("PATHEXT")
+	if pathext == "" {
+		pathext = ".com;.exe;.bat;.cmd"
+	}
+	for _, e := range strings.Split(strings.ToLower(pathext), ";") {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
@@ -13,10 +13,12 @@
 package filex
 
 import (
+	"bufio"
 	"bytes"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"io/ioutil"
 	"os"
 	"os/exec"
@@ -24,28 +26,83 @@ import (
 	"path/filepath"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
+// DirPerm 自动创建上级目录时使用的权限
+var DirPerm os.FileMode = 0755
+
 // Mkdir 给定文件的绝对路径创建文件
 func Mkdir(fpath string) error {
 	err := os.MkdirAll(fpath, os.ModePerm) // 生成多级目录
 	return err
 }
 
-// Create 给定文件的绝对路径创建文件
-func Create(filename string, src ...io.Reader) error {
+// MkdirMode 以指定权限创建多级目录, 实际权限受 umask 影响
+func MkdirMode(fpath string, perm os.FileMode) error {
+	return os.MkdirAll(fpath, perm)
+}
+
+// mkdirParent 以 DirPerm 权限创建文件所在目录(如不存在)
+func mkdirParent(filename string) error {
 	dir := Dir(filename)
-	var err error
-	if !Exists(dir) {
-		err = Mkdir(dir)
-		if err != nil {
-			return err
+	if Exists(dir) {
+		return nil
+	}
+	return MkdirMode(dir, DirPerm)
+}
+
+// ErrNotDir 路径已存在但不是目录
+var ErrNotDir = errors.New("not a directory")
+
+// ErrIsDir 路径已存在但是目录
+var ErrIsDir = errors.New("is a directory")
+
+// EnsureDir 确保目录存在, 不存在时以 perm 权限创建, 路径已存在但不是目录时返回 ErrNotDir
+func EnsureDir(path string, perm os.FileMode) error {
+	info, err := os.Stat(path)
+	if err == nil {
+		if !info.IsDir() {
+			return &os.PathError{Op: "ensuredir", Path: path, Err: ErrNotDir}
+		}
+		return nil
+	}
+	if !os.IsNotExist(err) {
+		return err
+	}
+	return MkdirMode(path, perm)
+}
+
+// EnsureFile 确保文件存在, 不存在时创建空文件及其所在目录, 已存在的文件内容保持不变
+// 路径已存在但是目录时返回 ErrIsDir
+func EnsureFile(path string) error {
+	info, err := os.Stat(path)
+	if err == nil {
+		if info.IsDir() {
+			return &os.PathError{Op: "ensurefile", Path: path, Err: ErrIsDir}
 		}
+		return nil
+	}
+	if !os.IsNotExist(err) {
+		return err
+	}
+	f, err := Open(path, os.O_WRONLY|os.O_CREATE)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// Create 给定文件的绝对路径创建文件
+func Create(filename string, src ...io.Reader) error {
+	if err := mkdirParent(filename); err != nil {
+		return err
 	}
 	if len(src) > 0 {
-		out, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE, 0666)
+		out, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
 		if err != nil {
 			return err
 		}
@@ -62,12 +119,96 @@ func Create(filename string, src ...io.Reader) error {
 	return nil
 }
 
-// Open 打开文件
+// CreateMode 同 Create, 以 perm 权限创建文件, 并在创建后 Chmod 以确保权限不受 umask 影响
+// 文件已存在时同样修改为 perm 权限
+func CreateMode(filename string, perm os.FileMode, src ...io.Reader) error {
+	if err := mkdirParent(filename); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if len(src) > 0 {
+		_, err = io.Copy(f, src[0])
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Chmod(filename, perm)
+}
+
+// CreateExcl 创建新文件并写入 src 中的内容, 文件已存在时返回 os.ErrExist 错误, 不会覆盖已有文件
+func CreateExcl(filename string, src io.Reader) error {
+	if err := mkdirParent(filename); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, src)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(filename)
+	}
+	return err
+}
+
+// CreateIfAbsent 文件不存在时创建文件并写入 content, 返回是否创建; 文件已存在时不做任何修改, 返回 false 及 nil 错误
+// 使用 O_EXCL 创建, 并发调用时只有一个调用者会创建成功
+func CreateIfAbsent(path, content string) (created bool, err error) {
+	err = CreateExcl(path, strings.NewReader(content))
+	if os.IsExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// WriteReader 创建(截断)文件并写入 r 中的全部内容, 返回写入的字节数
+// 自动创建所在目录, 写入后同步到磁盘; 失败时删除未完成的文件
+func WriteReader(path string, r io.Reader) (int64, error) {
+	if err := mkdirParent(path); err != nil {
+		return 0, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(f, r)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return n, err
+	}
+	return n, nil
+}
+
+// Open 打开文件, 默认读写模式打开, 文件不存在时创建
+// 打开标志包含 os.O_CREATE 时自动创建文件所在目录
 func Open(path string, pflag ...int) (*os.File, error) {
 	flag := os.O_RDWR | os.O_CREATE
 	if len(pflag) > 0 {
 		flag = pflag[0]
 	}
+	if flag&os.O_CREATE != 0 {
+		if err := mkdirParent(path); err != nil {
+			return nil, err
+		}
+	}
 
 	f, err := os.OpenFile(path, flag, 0666)
 	if err != nil {
@@ -76,6 +217,42 @@ func Open(path string, pflag ...int) (*os.File, error) {
 	return f, nil
 }
 
+// OpenWriter 以截断写入模式打开文件, 自动创建文件所在目录
+// 返回的 Writer 在 Close 时先同步到磁盘再关闭文件
+func OpenWriter(path string) (io.WriteCloser, error) {
+	f, err := Open(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return nil, err
+	}
+	return syncCloser{f}, nil
+}
+
+// OpenBuffered 以只读模式打开文件并返回带缓冲的 Reader 及关闭文件的函数
+// bufSize 为缓冲区大小, 不大于 0 时使用默认大小(32KB)
+func OpenBuffered(path string, bufSize int) (*bufio.Reader, func() error, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if bufSize <= 0 {
+		bufSize = copyBufferSize
+	}
+	return bufio.NewReaderSize(f, bufSize), f.Close, nil
+}
+
+// syncCloser 关闭前同步文件内容到磁盘
+type syncCloser struct {
+	*os.File
+}
+
+func (f syncCloser) Close() error {
+	err := f.File.Sync()
+	if cerr := f.File.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
 // Exists 判断所给路径文件/文件夹是否存在
 func Exists(path string) bool {
 	_, err := os.Stat(path)
@@ -99,9 +276,94 @@ func IsFile(path string) bool {
 	return !IsDir(path)
 }
 
-// Info 获取文件或目录信息
-func Info(path string) *os.FileInfo {
+// IsEmpty 判断文件是否为空(0 字节)或目录是否不包含任何条目(含隐藏文件)
+// 目录最多读取一个条目, 路径不存在时返回错误
+func IsEmpty(path string) (bool, error) {
 	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	if !info.IsDir() {
+		return info.Size() == 0, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	_, err = f.Readdirnames(1)
+	if err == io.EOF {
+		return true, nil
+	}
+	return false, err
+}
+
+// Stat 获取文件或目录信息
+func Stat(path string) (os.FileInfo, error) {
+	return os.Stat(path)
+}
+
+// Lstat 获取文件或目录信息, 路径为符号链接时返回链接自身的信息, 不跟随链接
+func Lstat(path string) (os.FileInfo, error) {
+	return os.Lstat(path)
+}
+
+// IsSymlink 判断所给路径是否为符号链接
+func IsSymlink(path string) bool {
+	info, err := Lstat(path)
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeSymlink != 0
+}
+
+// StatAll 使用 workers 个并发协程批量获取文件信息, workers <= 0 时使用 GOMAXPROCS
+// 成功的结果与失败的错误分别以路径为键返回; 重复的路径只获取一次
+func StatAll(paths []string, workers int) (map[string]os.FileInfo, map[string]error) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(paths) {
+		workers = len(paths)
+	}
+	infos := make(map[string]os.FileInfo, len(paths))
+	errs := make(map[string]error)
+	var mu sync.Mutex
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range jobs {
+				info, err := os.Stat(p)
+				mu.Lock()
+				if err != nil {
+					errs[p] = err
+				} else {
+					infos[p] = info
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	seen := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		if !seen[p] {
+			seen[p] = true
+			jobs <- p
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return infos, errs
+}
+
+// Info 获取文件或目录信息, 出错时返回 nil
+//
+// Deprecated: 返回值为接口指针, 使用不便, 请使用 Stat
+func Info(path string) *os.FileInfo {
+	info, err := Stat(path)
 	if err != nil {
 		return nil
 	}
@@ -126,6 +388,42 @@ func MTimeMS(path string) int64 {
 	return int64(f.ModTime().Nanosecond() / 1000000)
 }
 
+// Newer 判断 a 的修改时间是否晚于 b, 修改时间相同时返回 false, 任一路径不存在时返回错误
+func Newer(a, b string) (bool, error) {
+	ib, err := Stat(b)
+	if err != nil {
+		return false, err
+	}
+	return NewerThan(a, ib.ModTime())
+}
+
+// NewerThan 判断文件修改时间是否晚于 t, 修改时间等于 t 时返回 false
+func NewerThan(path string, t time.Time) (bool, error) {
+	info, err := Stat(path)
+	if err != nil {
+		return false, err
+	}
+	return info.ModTime().After(t), nil
+}
+
+// Age 返回文件自最后修改以来经过的时间
+func Age(path string) (time.Duration, error) {
+	info, err := Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return time.Since(info.ModTime()), nil
+}
+
+// OlderThan 判断文件最后修改时间是否早于 d 之前, 如 OlderThan(path, 24*time.Hour)
+func OlderThan(path string, d time.Duration) (bool, error) {
+	age, err := Age(path)
+	if err != nil {
+		return false, err
+	}
+	return age > d, nil
+}
+
 // Size 文件大小(bytes)
 func Size(path string) int64 {
 	f, e := os.Stat(path)
@@ -135,68 +433,119 @@ func Size(path string) int64 {
 	return f.Size()
 }
 
+// DirSize 目录大小(bytes), 递归累加目录下所有普通文件的大小
+// 符号链接不跟随, 也不计入大小
+func DirSize(path string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		total += info.Size()
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// ReadableDirSize 可读性强的目录大小字符串
+func ReadableDirSize(path string) string {
+	size, _ := DirSize(path)
+	return FormatSize(float64(size))
+}
+
 // ReadableSize 可读性强的文件大小字符串
 func ReadableSize(path string) string {
 	return FormatSize(float64(Size(path)))
 }
 
-// FormatSize 格式化文件大小
+// FormatSize 格式化文件大小, 以 1024 为进制, 保留两位小数, 单位为 B/K/M/G/T/P/E/Z/Y
 func FormatSize(raw float64) string {
-	var t float64 = 1024
-	var d float64 = 1
+	return FormatSizeOpts(raw, FormatOptions{Base: 1024, Precision: 2})
+}
 
-	if raw < t {
-		return fmt.Sprintf("%.2fB", raw/d)
-	}
+// SizeLabels 文件大小单位标签风格
+type SizeLabels int
 
-	d *= 1024
-	t *= 1024
+const (
+	// LabelShort 简写单位 B/K/M/G/T/P/E/Z/Y
+	LabelShort SizeLabels = iota
+	// LabelIEC IEC 二进制单位 B/KiB/MiB/GiB/TiB/PiB/EiB/ZiB/YiB
+	LabelIEC
+	// LabelSI SI 十进制单位 B/kB/MB/GB/TB/PB/EB/ZB/YB
+	LabelSI
+)
 
-	if raw < t {
-		return fmt.Sprintf("%.2fK", raw/d)
-	}
+var sizeLabels = map[SizeLabels][]string{
+	LabelShort: {"B", "K", "M", "G", "T", "P", "E", "Z", "Y"},
+	LabelIEC:   {"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB", "ZiB", "YiB"},
+	LabelSI:    {"B", "kB", "MB", "GB", "TB", "PB", "EB", "ZB", "YB"},
+}
 
-	d *= 1024
-	t *= 1024
+// FormatOptions 文件大小格式化选项
+type FormatOptions struct {
+	// Base 进制, 1000 或 1024, 为 0 时使用 1024
+	Base float64
+	// Precision 保留小数位数
+	Precision int
+	// Labels 单位标签风格
+	Labels SizeLabels
+}
 
-	if raw < t {
-		return fmt.Sprintf("%.2fM", raw/d)
+// FormatSizeOpts 按指定选项格式化文件大小, 负数大小输出带负号的结果
+// 超出最大单位的数值仍以最大单位表示
+func FormatSizeOpts(raw float64, opts FormatOptions) string {
+	base := opts.Base
+	if base <= 1 {
+		base = 1024
 	}
-
-	d *= 1024
-	t *= 1024
-
-	if raw < t {
-		return fmt.Sprintf("%.2fG", raw/d)
+	labels, ok := sizeLabels[opts.Labels]
+	if !ok {
+		labels = sizeLabels[LabelShort]
 	}
-
-	d *= 1024
-	t *= 1024
-
-	if raw < t {
-		return fmt.Sprintf("%.2fT", raw/d)
+	sign := ""
+	if raw < 0 {
+		sign = "-"
+		raw = -raw
 	}
-
-	d *= 1024
-	t *= 1024
-
-	if raw < t {
-		return fmt.Sprintf("%.2fP", raw/d)
+	i := 0
+	for raw >= base && i < len(labels)-1 {
+		raw /= base
+		i++
 	}
-
-	return "TooLarge"
+	// 按精度舍入后达到进制时(如 999.99KB 保留 1 位为 1000.0KB)进位到下一个单位
+	if i < len(labels)-1 {
+		if v, err := strconv.ParseFloat(strconv.FormatFloat(raw, 'f', opts.Precision, 64), 64); err == nil && v >= base {
+			raw /= base
+			i++
+		}
+	}
+	return fmt.Sprintf("%s%.*f%s", sign, opts.Precision, raw, labels[i])
 }
 
+// rename 重命名实现, 测试时可替换
+var rename = os.Rename
+
 // Move 文件移动/重命名
+// 源与目标位于不同文件系统(设备)时, 自动改为复制(保留权限及修改时间)后删除源文件, 目录会递归复制
 func Move(src string, dst string) error {
-	dir := Dir(dst)
-	if !Exists(dir) {
-		err := Mkdir(dir)
-		if err != nil {
-			return err
-		}
+	if err := mkdirParent(dst); err != nil {
+		return err
+	}
+	err := rename(src, dst)
+	if err != nil && isCrossDevice(err) {
+		return moveByCopy(src, dst)
 	}
-	return os.Rename(src, dst)
+	return err
 }
 
 // Rename 文件移动/重命名
@@ -210,33 +559,57 @@ func Copy(src string, dst string) error {
 	if err != nil {
 		return err
 	}
-	dir := Dir(dst)
-	if !Exists(dir) {
-		err := Mkdir(dir)
+	defer srcFile.Close()
+	_, err = CopyFrom(dst, srcFile)
+	return err
+}
+
+// Glob 文件名正则匹配查找
+func Glob(pattern string) ([]string, error) {
+	return filepath.Glob(pattern)
+}
+
+// GlobAny 多个模式匹配查找, 返回去重并排序后的结果
+func GlobAny(patterns ...string) ([]string, error) {
+	seen := make(map[string]bool)
+	var list []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		for _, m := range matches {
+			if !seen[m] {
+				seen[m] = true
+				list = append(list, m)
+			}
 		}
 	}
-	dstFile, err := os.Create(dst)
+	sort.Strings(list)
+	return list, nil
+}
+
+// GlobExcept 返回匹配 include 中任一模式, 且不匹配 exclude 中任何模式的文件, 结果去重并排序
+func GlobExcept(include []string, exclude []string) ([]string, error) {
+	list, err := GlobAny(include...)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	_, err = io.Copy(dstFile, srcFile)
+	excluded, err := GlobAny(exclude...)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	err = dstFile.Sync()
-	if err != nil {
-		return err
+	skip := make(map[string]bool, len(excluded))
+	for _, m := range excluded {
+		skip[m] = true
 	}
-	srcFile.Close()
-	dstFile.Close()
-	return nil
-}
-
-// Glob 文件名正则匹配查找
-func Glob(pattern string) ([]string, error) {
-	return filepath.Glob(pattern)
+	result := list[:0]
+	for _, m := range list {
+		if !skip[m] {
+			result = append(result, m)
+		}
+	}
+	return result, nil
 }
 
 // Remove 文件/目录删除
@@ -260,7 +633,7 @@ func IsWritable(path string) bool {
 	result := true
 	if IsDir(path) {
 		// 如果是目录，那么创建一个临时文件进行写入测试
-		tfile := strings.TrimRight(path, string(filepath.Separator)) + string(filepath.Separator) + string(time.Now().UnixNano())
+		tfile := strings.TrimRight(path, string(filepath.Separator)) + string(filepath.Separator) + strconv.FormatInt(time.Now().UnixNano(), 10)
 		err := Create(tfile)
 		if err != nil || !Exists(tfile) {
 			result = false
@@ -278,13 +651,75 @@ func IsWritable(path string) bool {
 	return result
 }
 
+// Chown 修改文件/目录的所有者及所属组, Windows 下不支持
+func Chown(path string, uid, gid int) error {
+	return os.Chown(path, uid, gid)
+}
+
+// CopyOwnership 将 src 的所有者及所属组(uid/gid)应用到 dst, 通常需要 root 权限
+// 符号链接跟随到目标文件; Windows 下不支持, 返回错误
+func CopyOwnership(src, dst string) error {
+	return copyOwnership(src, dst)
+}
+
+// IsExecutable 文件是否可执行, 目录返回 false
+// Unix 下要求设置了执行权限位且当前用户可执行; Windows 下没有执行权限位, 按扩展名(PATHEXT)判断
+func IsExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	return isExecutable(path, info)
+}
+
+// Mode 获取文件/目录的模式(类型及权限位)
+func Mode(path string) (os.FileMode, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return info.Mode(), nil
+}
+
+// Perm 获取文件/目录的权限位
+func Perm(path string) (os.FileMode, error) {
+	mode, err := Mode(path)
+	if err != nil {
+		return 0, err
+	}
+	return mode.Perm(), nil
+}
+
 // Chmod 修改文件/目录权限
 func Chmod(path string, mode os.FileMode) error {
 	return os.Chmod(path, mode)
 }
 
+// ListOptions 目录列表选项, 用于 ScanDir, DirEntries, ScanDirFiles, ScanSubdirs 等函数
+// 零值与不传入选项相同, 列出所有条目(包括隐藏文件)
+type ListOptions struct {
+	// SkipHidden 是否跳过隐藏文件/目录, Unix 下指以 . 开头的名称, Windows 下另包括设置了隐藏属性的条目
+	SkipHidden bool
+}
+
+// listHidden 判断列表选项是否包括隐藏条目
+func listHidden(opts []ListOptions) bool {
+	return len(opts) == 0 || !opts[0].SkipHidden
+}
+
 // ScanDir 打开目录，并返回其下一级子目录名称列表，按照文件名称大小写进行排序
-func ScanDir(path string) []string {
+func ScanDir(path string, opts ...ListOptions) []string {
+	if !listHidden(opts) {
+		entries, err := DirEntries(path, opts...)
+		if err != nil {
+			return nil
+		}
+		list := make([]string, len(entries))
+		for i, e := range entries {
+			list[i] = e.Name()
+		}
+		return list
+	}
 	f, err := os.Open(path)
 	if err != nil {
 		return nil
@@ -299,6 +734,72 @@ func ScanDir(path string) []string {
 	return list
 }
 
+// DirEntries 读取目录下一级的所有条目, 按名称排序
+// 条目类型来自读取目录时的结果, 无需逐个 Stat; 与 ScanDir 不同, 出错时返回错误
+func DirEntries(path string, opts ...ListOptions) ([]os.DirEntry, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	if listHidden(opts) {
+		return entries, nil
+	}
+	list := entries[:0]
+	for _, e := range entries {
+		if !isHidden(e) {
+			list = append(list, e)
+		}
+	}
+	return list, nil
+}
+
+// ScanDirFiles 返回目录下一级文件的名称列表(不含子目录), 按名称排序
+// 指向目录的符号链接视为子目录, 不包括在内
+func ScanDirFiles(path string, opts ...ListOptions) ([]string, error) {
+	return scanDir(path, false, opts)
+}
+
+// ScanSubdirs 返回目录下一级子目录的名称列表(不含文件), 按名称排序
+// 指向目录的符号链接视为子目录, 失效的符号链接忽略
+func ScanSubdirs(path string, opts ...ListOptions) ([]string, error) {
+	return scanDir(path, true, opts)
+}
+
+// ScanSubdirsFull 同 ScanSubdirs, 返回子目录的绝对路径
+func ScanSubdirsFull(path string, opts ...ListOptions) ([]string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	list, err := ScanSubdirs(abs, opts...)
+	if err != nil {
+		return nil, err
+	}
+	for i, name := range list {
+		list[i] = filepath.Join(abs, name)
+	}
+	return list, nil
+}
+
+// scanDir 返回目录下一级子目录(dirs 为 true)或文件的名称列表
+func scanDir(path string, dirs bool, opts []ListOptions) ([]string, error) {
+	entries, err := DirEntries(path, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var list []string
+	for _, e := range entries {
+		isDir := e.IsDir()
+		if e.Type()&os.ModeSymlink != 0 {
+			isDir = IsDir(filepath.Join(path, e.Name()))
+		}
+		if isDir == dirs {
+			list = append(list, e.Name())
+		}
+	}
+	return list, nil
+}
+
 // RealPath 将所给定的路径转换为绝对路径
 // 并判断文件路径是否存在，如果文件不存在，那么返回空字符串
 func RealPath(path string) string {
@@ -326,14 +827,66 @@ func GetBinContents(path string) []byte {
 	return data
 }
 
+// ReadInto 将文件内容读入调用方提供的 buf, 返回读取的字节数, 不会分配新的缓冲区
+// 文件大于 buf 时 buf 被填满, 并返回 len(buf) 及 io.ErrShortBuffer, 由调用方扩大 buf 后重试
+func ReadInto(path string, buf []byte) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	n, err := io.ReadFull(f, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return n, nil
+	}
+	if err != nil {
+		return n, err
+	}
+	// buf 已填满, 检查文件是否还有剩余数据
+	var probe [1]byte
+	m, err := f.Read(probe[:])
+	if m > 0 {
+		return n, io.ErrShortBuffer
+	}
+	if err != nil && err != io.EOF {
+		return n, err
+	}
+	return n, nil
+}
+
+// ErrFileTooLarge 文件大小超过限制
+var ErrFileTooLarge = errors.New("file too large")
+
+// GetBinContentsLimit (二进制)读取文件内容, 文件大于 max 字节时返回 ErrFileTooLarge 而不读取
+// 读取期间文件变大超过 max 时同样返回 ErrFileTooLarge
+func GetBinContentsLimit(path string, max int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > max {
+		return nil, &os.PathError{Op: "read", Path: path, Err: ErrFileTooLarge}
+	}
+	data, err := io.ReadAll(io.LimitReader(f, max+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > max {
+		return nil, &os.PathError{Op: "read", Path: path, Err: ErrFileTooLarge}
+	}
+	return data, nil
+}
+
 // putContents 写入文件内容
 func putContents(path string, data []byte, flag int, perm os.FileMode) error {
 	// 支持目录递归创建
-	dir := Dir(path)
-	if !Exists(dir) {
-		if err := Mkdir(dir); err != nil {
-			return err
-		}
+	if err := mkdirParent(path); err != nil {
+		return err
 	}
 	// 创建/打开文件
 	f, err := os.OpenFile(path, flag, perm)
@@ -352,7 +905,16 @@ func putContents(path string, data []byte, flag int, perm os.FileMode) error {
 
 // Truncate changes the size of the named file.
 func Truncate(path string, size int) error {
-	return os.Truncate(path, int64(size))
+	return TruncateSize(path, int64(size))
+}
+
+// TruncateSize 将文件截断或扩展为 size 字节, size 为 int64, 32 位平台上也可处理超过 2GB 的大小
+// 扩展部分以 0 填充(多数文件系统下为稀疏文件), size 为负数时返回错误
+func TruncateSize(path string, size int64) error {
+	if size < 0 {
+		return &os.PathError{Op: "truncate", Path: path, Err: errors.New("negative size")}
+	}
+	return os.Truncate(path, size)
 }
 
 // PutContents (文本)写入文件内容
@@ -365,6 +927,30 @@ func AppendContents(path string, content string) error {
 	return putContents(path, []byte(content), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
 }
 
+// AppendLine (文本)追加一行内容到文件末尾, 自动添加换行符 \n
+func AppendLine(path string, line string) error {
+	return AppendLines(path, []string{line})
+}
+
+// AppendLines (文本)追加多行内容到文件末尾, 每行自动添加换行符 \n
+// 所有行通过一次 Write 调用写入; Unix 下 O_APPEND 写入在 PIPE_BUF(通常 4096 字节)以内是原子的,
+// 多个 goroutine/进程并发追加时各自的内容不会相互穿插
+func AppendLines(path string, lines []string) error {
+	if len(lines) == 0 {
+		return nil
+	}
+	n := len(lines)
+	for _, line := range lines {
+		n += len(line)
+	}
+	buf := make([]byte, 0, n)
+	for _, line := range lines {
+		buf = append(buf, line...)
+		buf = append(buf, '\n')
+	}
+	return AppendBinContents(path, buf)
+}
+
 // PutBinContents (二进制)写入文件内容
 func PutBinContents(path string, content []byte) error {
 	return putContents(path, content, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
@@ -395,6 +981,16 @@ func Basename(path string) string {
 	return filepath.Base(path)
 }
 
+// Stem BasenameNoExt 别名
+var Stem = BasenameNoExt
+
+// BasenameNoExt 获取指定文件路径不含扩展名的文件名称, 如 /a/b/report.pdf 返回 report
+// 以 . 开头且不含其他 . 的文件(如 .env)原样返回
+func BasenameNoExt(path string) string {
+	name, _ := splitExt(Basename(path))
+	return name
+}
+
 // Dir 获取指定文件路径的目录地址绝对路径
 func Dir(filename string) string {
 	return filepath.Dir(filename)
@@ -405,10 +1001,72 @@ func Ext(path string) string {
 	return filepath.Ext(path)
 }
 
-// Home 获取用户主目录
+// ExtName 获取指定文件路径的文件扩展名, 不含 . 且转换为小写, 如 photo.JPG 返回 jpg
+// 以 . 开头且不含其他 . 的文件(如 .gitignore)视为没有扩展名
+func ExtName(path string) string {
+	_, ext := splitExt(Basename(path))
+	return strings.ToLower(strings.TrimPrefix(ext, "."))
+}
+
+// Exts 获取指定文件路径的所有扩展名(含.), 如 archive.tar.gz 返回 [.tar .gz]
+// 文件名开头的 . 不作为扩展名分隔符
+func Exts(path string) []string {
+	parts := strings.Split(strings.TrimLeft(Basename(path), "."), ".")
+	var exts []string
+	for _, part := range parts[1:] {
+		if part != "" {
+			exts = append(exts, "."+part)
+		}
+	}
+	return exts
+}
+
+var (
+	homeMu     sync.Mutex
+	homeCache  string
+	homeLookup = lookupHome
+)
+
+// Home 获取用户主目录, 首次查找成功后缓存结果
 func Home() (string, error) {
+	homeMu.Lock()
+	defer homeMu.Unlock()
+	if homeCache != "" {
+		return homeCache, nil
+	}
+	home, err := homeLookup()
+	if err != nil {
+		return "", err
+	}
+	homeCache = home
+	return home, nil
+}
+
+// MustHome 获取用户主目录, 出错时 panic
+func MustHome() string {
+	home, err := Home()
+	if err != nil {
+		panic(err)
+	}
+	return home
+}
+
+// HomeOr 获取用户主目录, 出错时返回 fallback
+func HomeOr(fallback string) string {
+	home, err := Home()
+	if err != nil {
+		return fallback
+	}
+	return home
+}
+
+// lookupHome 依次通过 os.UserHomeDir, 当前用户信息, 环境变量/shell 查找用户主目录
+func lookupHome() (string, error) {
+	if home, err := os.UserHomeDir(); err == nil && home != "" {
+		return home, nil
+	}
 	u, err := user.Current()
-	if nil == err {
+	if nil == err && u.HomeDir != "" {
 		return u.HomeDir, nil
 	}
 	if "windows" == runtime.GOOS {
@@ -480,3 +1138,133 @@ func GetBinContentByTwoOffsets(file *os.File, start int64, end int64) []byte {
 func TempDir() string {
 	return os.TempDir()
 }
+
+// MakeTempDir 在系统临时目录下创建新的临时目录, pattern 规则同 os.MkdirTemp
+func MakeTempDir(pattern string) (string, error) {
+	return os.MkdirTemp("", pattern)
+}
+
+// WithTempDir 创建临时目录并执行 fn, 结束后(包括 fn 出错或 panic)通过 Remove 删除整个目录
+// 返回 fn 的错误, fn 成功时返回删除目录的错误
+func WithTempDir(pattern string, fn func(dir string) error) (err error) {
+	dir, err := MakeTempDir(pattern)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		r := recover()
+		if rerr := Remove(dir); err == nil {
+			err = rerr
+		}
+		if r != nil {
+			panic(r)
+		}
+	}()
+	return fn(dir)
+}
+
+// ReadAt 从文件 offset 位置读取 length 字节
+// 与 io.ReadFull 语义一致: 未读到任何数据返回 io.EOF, 只读到部分数据时返回已读内容及 io.ErrUnexpectedEOF
+func ReadAt(path string, offset, length int64) ([]byte, error) {
+	if offset < 0 || length < 0 {
+		return nil, errors.New("negative offset or length")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	buf := make([]byte, length)
+	n, err := f.ReadAt(buf, offset)
+	if err == io.EOF {
+		if n == 0 && length > 0 {
+			return buf[:0], io.EOF
+		}
+		if n < len(buf) {
+			return buf[:n], io.ErrUnexpectedEOF
+		}
+		err = nil
+	}
+	if err != nil {
+		return buf[:n], err
+	}
+	return buf, nil
+}
+
+// WriteAt 在文件 offset 位置写入 data, 文件不存在时创建, 超出文件末尾时扩展文件
+func WriteAt(path string, offset int64, data []byte) error {
+	if offset < 0 {
+		return errors.New("negative offset")
+	}
+	f, err := Open(path, os.O_WRONLY|os.O_CREATE)
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteAt(data, offset)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// SameContent 判断两个文件内容是否完全相同, 大小不同时直接返回 false, 否则分块流式比较
+func SameContent(a, b string) (bool, error) {
+	ia, err := os.Stat(a)
+	if err != nil {
+		return false, err
+	}
+	ib, err := os.Stat(b)
+	if err != nil {
+		return false, err
+	}
+	if ia.Size() != ib.Size() {
+		return false, nil
+	}
+	if os.SameFile(ia, ib) {
+		return true, nil
+	}
+	fa, err := os.Open(a)
+	if err != nil {
+		return false, err
+	}
+	defer fa.Close()
+	fb, err := os.Open(b)
+	if err != nil {
+		return false, err
+	}
+	defer fb.Close()
+
+	ba := make([]byte, copyBufferSize)
+	bb := make([]byte, copyBufferSize)
+	for {
+		na, erra := io.ReadFull(fa, ba)
+		nb, errb := io.ReadFull(fb, bb)
+		if !bytes.Equal(ba[:na], bb[:nb]) {
+			return false, nil
+		}
+		eofa := erra == io.EOF || erra == io.ErrUnexpectedEOF
+		eofb := errb == io.EOF || errb == io.ErrUnexpectedEOF
+		if erra != nil && !eofa {
+			return false, erra
+		}
+		if errb != nil && !eofb {
+			return false, errb
+		}
+		if eofa || eofb {
+			return eofa == eofb, nil
+		}
+	}
+}
+
+// SameFile 判断两个路径是否指向同一个文件(如硬链接, 相同 inode)
+func SameFile(a, b string) (bool, error) {
+	ia, err := os.Stat(a)
+	if err != nil {
+		return false, err
+	}
+	ib, err := os.Stat(b)
+	if err != nil {
+		return false, err
+	}
+	return os.SameFile(ia, ib), nil
+}
@@ -0,0 +1,695 @@
+package filex
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirSize(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	assert.Nil(PutContents(filepath.Join(dir, "a.txt"), strings.Repeat("a", 100)))
+	assert.Nil(PutContents(filepath.Join(dir, "sub", "b.txt"), strings.Repeat("b", 200)))
+	assert.Nil(PutContents(filepath.Join(dir, "sub", "deep", "c.txt"), strings.Repeat("c", 1024)))
+	assert.Nil(Mkdir(filepath.Join(dir, "empty")))
+	if runtime.GOOS != "windows" {
+		assert.Nil(os.Symlink(filepath.Join(dir, "sub", "deep", "c.txt"), filepath.Join(dir, "link")))
+	}
+
+	size, err := DirSize(dir)
+	assert.Nil(err)
+	assert.Equal(int64(1324), size)
+	assert.Equal("1.29K", ReadableDirSize(dir))
+
+	_, err = DirSize(filepath.Join(dir, "missing"))
+	assert.NotNil(err)
+}
+
+func TestFormatSize(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("0.00B", FormatSize(0))
+	assert.Equal("1023.00B", FormatSize(1023))
+	assert.Equal("1.00K", FormatSize(1024))
+	assert.Equal("1000.00B", FormatSize(1000))
+	assert.Equal("1.50M", FormatSize(1.5*1024*1024))
+	assert.Equal("1.00P", FormatSize(1<<50))
+	assert.Equal("1.00E", FormatSize(1<<60))
+	assert.Equal("16.00E", FormatSize(1<<64))
+}
+
+func TestFormatSizeOpts(t *testing.T) {
+	assert := assert.New(t)
+	si := FormatOptions{Base: 1000, Precision: 1, Labels: LabelSI}
+	assert.Equal("1.0kB", FormatSizeOpts(1000, si))
+	assert.Equal("1.0kB", FormatSizeOpts(1024, si))
+	assert.Equal("999.0B", FormatSizeOpts(999, si))
+	assert.Equal("2.5GB", FormatSizeOpts(2.5e9, si))
+	assert.Equal("1.0EB", FormatSizeOpts(1e18, si))
+
+	iec := FormatOptions{Base: 1024, Precision: 3, Labels: LabelIEC}
+	assert.Equal("1.000KiB", FormatSizeOpts(1024, iec))
+	assert.Equal("1000.000B", FormatSizeOpts(1000, iec))
+	assert.Equal("1.000EiB", FormatSizeOpts(1<<60, iec))
+
+	assert.Equal("2K", FormatSizeOpts(2048, FormatOptions{}))
+
+	// 舍入后达到进制时进位到下一个单位
+	for _, c := range []struct {
+		raw  float64
+		opts FormatOptions
+		want string
+	}{
+		{999999, si, "1.0MB"},
+		{999949, si, "999.9kB"},
+		{999.96, si, "1.0kB"},
+		{1048575, FormatOptions{Base: 1024, Precision: 0}, "1M"},
+		{1048063, FormatOptions{Base: 1024, Precision: 0}, "1023K"},
+		{1023.6, FormatOptions{Base: 1024, Precision: 0}, "1K"},
+		{-999999, si, "-1.0MB"},
+		{999.99e24, si, "1000.0YB"},
+	} {
+		assert.Equal(c.want, FormatSizeOpts(c.raw, c.opts), "%v", c.raw)
+	}
+}
+
+func TestFormatSizeLarge(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("5.00E", FormatSize(5*(1<<60)))
+	assert.Equal("1.00Z", FormatSize(1<<70))
+	assert.Equal("1.00Y", FormatSize(1<<80))
+	assert.Equal("2048.00Y", FormatSize(1<<91))
+	assert.Equal("3.0EB", FormatSizeOpts(3e18, FormatOptions{Base: 1000, Precision: 1, Labels: LabelSI}))
+
+	assert.Equal("-1.00K", FormatSize(-1024))
+	assert.Equal("-512.00B", FormatSize(-512))
+	assert.Equal("-2.00E", FormatSize(-2*(1<<60)))
+}
+
+func TestOpenCreatesParents(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+
+	p := filepath.Join(dir, "a", "b", "c.txt")
+	f, err := Open(p)
+	assert.Nil(err)
+	_, err = f.WriteString("hello")
+	assert.Nil(err)
+	assert.Nil(f.Close())
+	assert.Equal("hello", GetContents(p))
+
+	_, err = Open(filepath.Join(dir, "x", "y.txt"), os.O_RDONLY)
+	assert.NotNil(err)
+	assert.False(Exists(filepath.Join(dir, "x")), "no directory without O_CREATE")
+}
+
+func TestReadAtWriteAt(t *testing.T) {
+	assert := assert.New(t)
+	p := filepath.Join(t.TempDir(), "data.bin")
+	assert.Nil(PutContents(p, "0123456789"))
+
+	data, err := ReadAt(p, 0, 4)
+	assert.Nil(err)
+	assert.Equal("0123", string(data))
+
+	data, err = ReadAt(p, 8, 4)
+	assert.Equal(io.ErrUnexpectedEOF, err)
+	assert.Equal("89", string(data))
+
+	data, err = ReadAt(p, 20, 4)
+	assert.Equal(io.EOF, err)
+	assert.Len(data, 0)
+
+	data, err = ReadAt(p, 0, 10)
+	assert.Nil(err)
+	assert.Equal("0123456789", string(data))
+
+	assert.Nil(WriteAt(p, 0, []byte("ab")))
+	assert.Equal("ab23456789", GetContents(p))
+	assert.Nil(WriteAt(p, 12, []byte("xy")))
+	assert.Equal("ab23456789\x00\x00xy", GetContents(p))
+	assert.Nil(WriteAt(filepath.Join(filepath.Dir(p), "new", "f.bin"), 0, []byte("n")))
+	assert.Equal("n", GetContents(filepath.Join(filepath.Dir(p), "new", "f.bin")))
+
+	_, err = ReadAt(p, -1, 1)
+	assert.NotNil(err)
+}
+
+func TestEnsureDirFile(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+
+	d := filepath.Join(dir, "a", "b")
+	assert.Nil(EnsureDir(d, 0755))
+	assert.True(IsDir(d))
+	assert.Nil(EnsureDir(d, 0755), "existing directory is fine")
+
+	f := filepath.Join(dir, "x", "y", "f.txt")
+	assert.Nil(EnsureFile(f))
+	assert.True(Exists(f))
+	assert.Nil(PutContents(f, "keep"))
+	assert.Nil(EnsureFile(f), "existing file is fine")
+	assert.Equal("keep", GetContents(f))
+
+	err := EnsureDir(f, 0755)
+	assert.True(errors.Is(err, ErrNotDir))
+	err = EnsureFile(d)
+	assert.True(errors.Is(err, ErrIsDir))
+}
+
+func TestIsEmpty(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+
+	empty := filepath.Join(dir, "empty.txt")
+	assert.Nil(Create(empty))
+	ok, err := IsEmpty(empty)
+	assert.Nil(err)
+	assert.True(ok)
+
+	full := filepath.Join(dir, "full.txt")
+	assert.Nil(PutContents(full, "x"))
+	ok, err = IsEmpty(full)
+	assert.Nil(err)
+	assert.False(ok)
+
+	emptyDir := filepath.Join(dir, "emptydir")
+	assert.Nil(Mkdir(emptyDir))
+	ok, err = IsEmpty(emptyDir)
+	assert.Nil(err)
+	assert.True(ok)
+
+	hidden := filepath.Join(dir, "hidden")
+	assert.Nil(PutContents(filepath.Join(hidden, ".keep"), ""))
+	ok, err = IsEmpty(hidden)
+	assert.Nil(err)
+	assert.False(ok)
+
+	_, err = IsEmpty(filepath.Join(dir, "missing"))
+	assert.True(os.IsNotExist(err))
+}
+
+func TestSameContent(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	big := strings.Repeat("0123456789", 10000)
+	a := filepath.Join(dir, "a")
+	b := filepath.Join(dir, "b")
+	c := filepath.Join(dir, "c")
+	d := filepath.Join(dir, "d")
+	assert.Nil(PutContents(a, big))
+	assert.Nil(PutContents(b, big))
+	assert.Nil(PutContents(c, big[:len(big)-1]+"X"))
+	assert.Nil(PutContents(d, big+"!"))
+
+	same, err := SameContent(a, b)
+	assert.Nil(err)
+	assert.True(same)
+	same, err = SameContent(a, c)
+	assert.Nil(err)
+	assert.False(same, "same size, different content")
+	same, err = SameContent(a, d)
+	assert.Nil(err)
+	assert.False(same)
+	_, err = SameContent(a, filepath.Join(dir, "missing"))
+	assert.NotNil(err)
+
+	same, err = SameFile(a, b)
+	assert.Nil(err)
+	assert.False(same)
+	link := filepath.Join(dir, "link")
+	assert.Nil(os.Link(a, link))
+	same, err = SameFile(a, link)
+	assert.Nil(err)
+	assert.True(same)
+	same, err = SameFile(a, filepath.Join(dir, ".", "a"))
+	assert.Nil(err)
+	assert.True(same)
+}
+
+func TestStat(t *testing.T) {
+	assert := assert.New(t)
+	p := filepath.Join(t.TempDir(), "info.txt")
+	assert.Nil(PutContents(p, "12345"))
+
+	info, err := Stat(p)
+	assert.Nil(err)
+	assert.Equal("info.txt", info.Name())
+	assert.Equal(int64(5), info.Size())
+
+	_, err = Stat(p + ".missing")
+	assert.True(os.IsNotExist(err))
+	assert.Equal(int64(5), (*Info(p)).Size())
+	assert.Nil(Info(p + ".missing"))
+}
+
+func TestCreateTruncates(t *testing.T) {
+	assert := assert.New(t)
+	p := filepath.Join(t.TempDir(), "f.txt")
+	assert.Nil(Create(p, strings.NewReader("a much longer original content")))
+	assert.Nil(Create(p, strings.NewReader("short")))
+	assert.Equal("short", GetContents(p))
+
+	err := CreateExcl(p, strings.NewReader("other"))
+	assert.True(os.IsExist(err))
+	assert.Equal("short", GetContents(p))
+
+	q := filepath.Join(filepath.Dir(p), "sub", "new.txt")
+	assert.Nil(CreateExcl(q, strings.NewReader("fresh")))
+	assert.Equal("fresh", GetContents(q))
+}
+
+func TestGlobAnyExcept(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	for _, name := range []string{"a.go", "b.go", "a_test.go", "c.md", "d.txt"} {
+		assert.Nil(PutContents(filepath.Join(dir, name), ""))
+	}
+	j := func(name string) string { return filepath.Join(dir, name) }
+
+	list, err := GlobAny(j("*.go"), j("a*"), j("*.md"))
+	assert.Nil(err)
+	assert.Equal([]string{j("a.go"), j("a_test.go"), j("b.go"), j("c.md")}, list)
+
+	list, err = GlobExcept([]string{j("*.go"), j("*.md")}, []string{j("*_test.go"), j("c.*")})
+	assert.Nil(err)
+	assert.Equal([]string{j("a.go"), j("b.go")}, list)
+
+	_, err = GlobAny("[")
+	assert.NotNil(err)
+}
+
+func TestHomeCache(t *testing.T) {
+	assert := assert.New(t)
+	defer func() {
+		homeLookup = lookupHome
+		homeCache = ""
+	}()
+
+	calls := 0
+	fail := true
+	homeLookup = func() (string, error) {
+		calls++
+		if fail {
+			return "", errors.New("no home")
+		}
+		return "/home/gox", nil
+	}
+	homeCache = ""
+
+	_, err := Home()
+	assert.NotNil(err)
+	assert.Equal("/fallback", HomeOr("/fallback"))
+	assert.Panics(func() { MustHome() })
+	assert.Equal(3, calls, "failures are not cached")
+
+	fail = false
+	for i := 0; i < 5; i++ {
+		home, err := Home()
+		assert.Nil(err)
+		assert.Equal("/home/gox", home)
+	}
+	assert.Equal("/home/gox", MustHome())
+	assert.Equal("/home/gox", HomeOr("/fallback"))
+	assert.Equal(4, calls, "lookup happens once after success")
+}
+
+func TestWriteReader(t *testing.T) {
+	assert := assert.New(t)
+	p := filepath.Join(t.TempDir(), "sub", "upload.bin")
+	assert.Nil(PutContents(p, "previous longer content"))
+
+	n, err := WriteReader(p, strings.NewReader("hello world"))
+	assert.Nil(err)
+	assert.Equal(int64(11), n)
+	assert.Equal("hello world", GetContents(p))
+
+	r := io.MultiReader(strings.NewReader("partial"), iotest.ErrReader(errors.New("connection reset")))
+	n, err = WriteReader(p, r)
+	assert.EqualError(err, "connection reset")
+	assert.Equal(int64(7), n)
+	assert.False(Exists(p), "partial file should be removed")
+}
+
+func TestWithTempDir(t *testing.T) {
+	assert := assert.New(t)
+	var dir string
+	err := WithTempDir("filex-*", func(d string) error {
+		dir = d
+		assert.True(IsDir(d))
+		return PutContents(filepath.Join(d, "sub", "f.txt"), "x")
+	})
+	assert.Nil(err)
+	assert.True(strings.HasPrefix(Basename(dir), "filex-"))
+	assert.False(Exists(dir))
+
+	err = WithTempDir("", func(d string) error {
+		dir = d
+		return errors.New("failed")
+	})
+	assert.EqualError(err, "failed")
+	assert.False(Exists(dir))
+
+	assert.PanicsWithValue("boom", func() {
+		WithTempDir("", func(d string) error {
+			dir = d
+			panic("boom")
+		})
+	})
+	assert.False(Exists(dir))
+
+	d, err := MakeTempDir("")
+	assert.Nil(err)
+	assert.True(IsDir(d))
+	assert.Nil(Remove(d))
+}
+
+func TestTruncateSize(t *testing.T) {
+	assert := assert.New(t)
+	p := filepath.Join(t.TempDir(), "big.bin")
+	assert.Nil(PutContents(p, "0123456789"))
+
+	assert.Nil(TruncateSize(p, 4))
+	assert.Equal("0123", GetContents(p))
+	assert.Nil(Truncate(p, 2))
+	assert.Equal("01", GetContents(p))
+
+	err := TruncateSize(p, -1)
+	assert.EqualError(err, "truncate "+p+": negative size")
+	assert.Equal(int64(2), Size(p))
+
+	const big = 3 << 30
+	if err := TruncateSize(p, big); err != nil {
+		t.Skipf("sparse files not supported: %v", err)
+	}
+	assert.Equal(int64(big), Size(p))
+	data, err := ReadAt(p, big-4, 4)
+	assert.Nil(err)
+	assert.Equal([]byte{0, 0, 0, 0}, data)
+}
+
+func TestNewer(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	assert.Nil(PutContents(a, "a"))
+	assert.Nil(PutContents(b, "b"))
+	base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Nil(os.Chtimes(a, base, base.Add(time.Hour)))
+	assert.Nil(os.Chtimes(b, base, base))
+
+	ok, err := Newer(a, b)
+	assert.Nil(err)
+	assert.True(ok)
+	ok, err = Newer(b, a)
+	assert.Nil(err)
+	assert.False(ok)
+
+	// 修改时间相同不算更新
+	assert.Nil(os.Chtimes(b, base, base.Add(time.Hour)))
+	ok, err = Newer(a, b)
+	assert.Nil(err)
+	assert.False(ok)
+
+	ok, err = NewerThan(a, base)
+	assert.Nil(err)
+	assert.True(ok)
+	ok, err = NewerThan(a, base.Add(time.Hour))
+	assert.Nil(err)
+	assert.False(ok)
+
+	_, err = Newer(a, filepath.Join(dir, "missing"))
+	assert.True(os.IsNotExist(err))
+	_, err = Newer(filepath.Join(dir, "missing"), b)
+	assert.True(os.IsNotExist(err))
+}
+
+func TestGetBinContentsLimit(t *testing.T) {
+	assert := assert.New(t)
+	p := filepath.Join(t.TempDir(), "upload.bin")
+	assert.Nil(PutContents(p, "0123456789"))
+
+	data, err := GetBinContentsLimit(p, 100)
+	assert.Nil(err)
+	assert.Equal("0123456789", string(data))
+
+	data, err = GetBinContentsLimit(p, 10)
+	assert.Nil(err)
+	assert.Equal("0123456789", string(data))
+
+	data, err = GetBinContentsLimit(p, 9)
+	assert.True(errors.Is(err, ErrFileTooLarge))
+	assert.Nil(data)
+
+	_, err = GetBinContentsLimit(filepath.Join(t.TempDir(), "missing"), 10)
+	assert.True(os.IsNotExist(err))
+}
+
+func TestOpenWriter(t *testing.T) {
+	assert := assert.New(t)
+	p := filepath.Join(t.TempDir(), "out", "config.json")
+	assert.Nil(PutContents(p, "previous content that is longer"))
+
+	w, err := OpenWriter(p)
+	assert.Nil(err)
+	assert.Nil(json.NewEncoder(w).Encode(map[string]int{"port": 8080}))
+	assert.Nil(w.Close())
+	assert.Equal("{\"port\":8080}\n", GetContents(p))
+	assert.NotNil(w.Close())
+
+	_, err = OpenWriter(t.TempDir())
+	assert.NotNil(err)
+}
+
+func TestOpenBuffered(t *testing.T) {
+	assert := assert.New(t)
+	p := filepath.Join(t.TempDir(), "big.txt")
+	var b strings.Builder
+	for i := 0; i < 100000; i++ {
+		b.WriteString("line " + strconv.Itoa(i) + "\n")
+	}
+	assert.Nil(PutContents(p, b.String()))
+
+	r, closeFn, err := OpenBuffered(p, 0)
+	assert.Nil(err)
+	assert.Equal(copyBufferSize, r.Size())
+	n := 0
+	for {
+		line, err := r.ReadString('\n')
+		if err == io.EOF {
+			break
+		}
+		assert.Nil(err)
+		assert.Equal("line "+strconv.Itoa(n)+"\n", line)
+		n++
+	}
+	assert.Equal(100000, n)
+	assert.Nil(closeFn())
+	assert.True(errors.Is(closeFn(), os.ErrClosed), "descriptor is released")
+	assert.Nil(os.Remove(p))
+
+	p = filepath.Join(t.TempDir(), "small.txt")
+	assert.Nil(PutContents(p, strings.Repeat("x", 1024)))
+	r, closeFn, err = OpenBuffered(p, 64)
+	assert.Nil(err)
+	assert.Equal(64, r.Size())
+	_, err = r.ReadByte()
+	assert.Nil(err)
+	assert.Nil(closeFn())
+	_, err = r.Discard(1023)
+	assert.True(errors.Is(err, os.ErrClosed), "reads past the buffer fail after close")
+
+	_, _, err = OpenBuffered(filepath.Join(t.TempDir(), "missing"), 0)
+	assert.True(os.IsNotExist(err))
+}
+
+func TestCreateIfAbsent(t *testing.T) {
+	assert := assert.New(t)
+	p := filepath.Join(t.TempDir(), "conf", "app.ini")
+
+	created, err := CreateIfAbsent(p, "default")
+	assert.Nil(err)
+	assert.True(created)
+	created, err = CreateIfAbsent(p, "other")
+	assert.Nil(err)
+	assert.False(created)
+	assert.Equal("default", GetContents(p))
+
+	q := filepath.Join(t.TempDir(), "race.ini")
+	var wins int32
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			created, err := CreateIfAbsent(q, strconv.Itoa(i))
+			assert.Nil(err)
+			if created {
+				atomic.AddInt32(&wins, 1)
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(int32(1), wins)
+}
+
+func TestStatAll(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	var paths []string
+	for i := 0; i < 50; i++ {
+		p := filepath.Join(dir, strconv.Itoa(i)+".txt")
+		assert.Nil(PutContents(p, strings.Repeat("x", i)))
+		paths = append(paths, p)
+	}
+	missing := []string{filepath.Join(dir, "missing1"), filepath.Join(dir, "missing2")}
+	paths = append(paths, missing...)
+	paths = append(paths, paths[0])
+
+	infos, errs := StatAll(paths, 4)
+	assert.Len(infos, 50)
+	assert.Len(errs, 2)
+	assert.Equal(int64(7), infos[filepath.Join(dir, "7.txt")].Size())
+	for _, p := range missing {
+		assert.True(os.IsNotExist(errs[p]))
+	}
+
+	infos, errs = StatAll(nil, 0)
+	assert.Empty(infos)
+	assert.Empty(errs)
+}
+
+func TestListOptionsHidden(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	for _, p := range []string{".env", "a.txt", ".git/config", "src/main.go"} {
+		assert.Nil(PutContents(filepath.Join(dir, p), ""))
+	}
+	hide := ListOptions{SkipHidden: true}
+
+	assert.Equal([]string{".env", ".git", "a.txt", "src"}, ScanDir(dir))
+	assert.Equal([]string{".env", ".git", "a.txt", "src"}, ScanDir(dir, ListOptions{}))
+	assert.Equal([]string{"a.txt", "src"}, ScanDir(dir, hide))
+
+	entries, err := DirEntries(dir, hide)
+	assert.Nil(err)
+	assert.Len(entries, 2)
+
+	files, err := ScanDirFiles(dir)
+	assert.Nil(err)
+	assert.Equal([]string{".env", "a.txt"}, files)
+	files, err = ScanDirFiles(dir, ListOptions{})
+	assert.Nil(err)
+	assert.Equal([]string{".env", "a.txt"}, files)
+	files, err = ScanDirFiles(dir, hide)
+	assert.Nil(err)
+	assert.Equal([]string{"a.txt"}, files)
+
+	dirs, err := ScanSubdirs(dir, hide)
+	assert.Nil(err)
+	assert.Equal([]string{"src"}, dirs)
+
+	_, err = ScanDirFiles(filepath.Join(dir, "missing"))
+	assert.True(os.IsNotExist(err))
+}
+
+func TestReadInto(t *testing.T) {
+	assert := assert.New(t)
+	p := filepath.Join(t.TempDir(), "f.txt")
+	assert.Nil(PutContents(p, "0123456789"))
+
+	buf := make([]byte, 10)
+	n, err := ReadInto(p, buf)
+	assert.Nil(err)
+	assert.Equal(10, n)
+	assert.Equal("0123456789", string(buf))
+
+	buf = make([]byte, 4)
+	n, err = ReadInto(p, buf)
+	assert.Equal(io.ErrShortBuffer, err)
+	assert.Equal(4, n)
+	assert.Equal("0123", string(buf))
+
+	buf = make([]byte, 64)
+	n, err = ReadInto(p, buf)
+	assert.Nil(err)
+	assert.Equal("0123456789", string(buf[:n]))
+
+	assert.Nil(PutContents(p, ""))
+	n, err = ReadInto(p, buf)
+	assert.Nil(err)
+	assert.Equal(0, n)
+}
+
+func benchmarkFiles(b *testing.B) []string {
+	dir := b.TempDir()
+	paths := make([]string, 16)
+	for i := range paths {
+		paths[i] = filepath.Join(dir, strconv.Itoa(i))
+		if err := PutContents(paths[i], strings.Repeat("x", 2048)); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return paths
+}
+
+func BenchmarkReadInto(b *testing.B) {
+	paths := benchmarkFiles(b)
+	buf := make([]byte, 4096)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ReadInto(paths[i%len(paths)], buf); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetBinContents(b *testing.B) {
+	paths := benchmarkFiles(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if GetBinContents(paths[i%len(paths)]) == nil {
+			b.Fatal("read failed")
+		}
+	}
+}
+
+func TestAgeOlderThan(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	old, fresh := filepath.Join(dir, "old.tmp"), filepath.Join(dir, "fresh.tmp")
+	assert.Nil(PutContents(old, ""))
+	assert.Nil(PutContents(fresh, ""))
+	past := time.Now().Add(-48 * time.Hour)
+	assert.Nil(os.Chtimes(old, past, past))
+
+	age, err := Age(old)
+	assert.Nil(err)
+	assert.True(age >= 48*time.Hour && age < 49*time.Hour, "age %v", age)
+
+	ok, err := OlderThan(old, 24*time.Hour)
+	assert.Nil(err)
+	assert.True(ok)
+	ok, err = OlderThan(fresh, 24*time.Hour)
+	assert.Nil(err)
+	assert.False(ok)
+
+	_, err = Age(filepath.Join(dir, "missing"))
+	assert.True(os.IsNotExist(err))
+	_, err = OlderThan(filepath.Join(dir, "missing"), time.Hour)
+	assert.True(os.IsNotExist(err))
+}
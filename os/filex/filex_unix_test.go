@@ -0,0 +1,412 @@
+//go:build unix
+
+package filex
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func umask() os.FileMode {
+	m := syscall.Umask(0)
+	syscall.Umask(m)
+	return os.FileMode(m)
+}
+
+func TestMkdirMode(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	mask := umask()
+
+	p := filepath.Join(dir, "a", "b")
+	assert.Nil(MkdirMode(p, 0700))
+	info, err := os.Stat(p)
+	assert.Nil(err)
+	assert.Equal(0700&^mask, info.Mode().Perm())
+
+	assert.Nil(PutContents(filepath.Join(dir, "c", "d", "f.txt"), "x"))
+	info, err = os.Stat(filepath.Join(dir, "c", "d"))
+	assert.Nil(err)
+	assert.Equal(DirPerm&^mask, info.Mode().Perm())
+}
+
+func TestLstat(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target.txt")
+	link := filepath.Join(dir, "link")
+	assert.Nil(PutContents(target, "data"))
+	assert.Nil(os.Symlink(target, link))
+
+	info, err := Stat(link)
+	assert.Nil(err)
+	assert.True(info.Mode().IsRegular())
+	assert.Equal(int64(4), info.Size())
+
+	linfo, err := Lstat(link)
+	assert.Nil(err)
+	assert.True(linfo.Mode()&os.ModeSymlink != 0)
+	assert.Equal("link", linfo.Name())
+
+	assert.True(IsSymlink(link))
+	assert.False(IsSymlink(target))
+	assert.False(IsSymlink(filepath.Join(dir, "missing")))
+}
+
+func TestIsExecutable(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	script := filepath.Join(dir, "run.sh")
+	plain := filepath.Join(dir, "data.txt")
+	assert.Nil(PutContents(script, "#!/bin/sh\n"))
+	assert.Nil(PutContents(plain, "x"))
+	assert.Nil(os.Chmod(script, 0755))
+	assert.Nil(os.Chmod(plain, 0644))
+
+	assert.True(IsExecutable(script))
+	assert.False(IsExecutable(plain))
+	assert.False(IsExecutable(dir))
+	assert.False(IsExecutable(filepath.Join(dir, "missing")))
+
+	perm, err := Perm(script)
+	assert.Nil(err)
+	assert.Equal(os.FileMode(0755), perm)
+	mode, err := Mode(dir)
+	assert.Nil(err)
+	assert.True(mode.IsDir())
+	_, err = Perm(filepath.Join(dir, "missing"))
+	assert.True(os.IsNotExist(err))
+}
+
+func TestScanSubdirs(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	assert.Nil(PutContents(filepath.Join(dir, "file.txt"), ""))
+	assert.Nil(Mkdir(filepath.Join(dir, "b")))
+	assert.Nil(Mkdir(filepath.Join(dir, "a", "nested")))
+	assert.Nil(os.Symlink(filepath.Join(dir, "b"), filepath.Join(dir, "link-dir")))
+	assert.Nil(os.Symlink(filepath.Join(dir, "file.txt"), filepath.Join(dir, "link-file")))
+	assert.Nil(os.Symlink(filepath.Join(dir, "missing"), filepath.Join(dir, "broken")))
+
+	names, err := ScanSubdirs(dir)
+	assert.Nil(err)
+	assert.Equal([]string{"a", "b", "link-dir"}, names)
+
+	full, err := ScanSubdirsFull(dir)
+	assert.Nil(err)
+	assert.Equal([]string{filepath.Join(dir, "a"), filepath.Join(dir, "b"), filepath.Join(dir, "link-dir")}, full)
+
+	_, err = ScanSubdirs(filepath.Join(dir, "missing"))
+	assert.True(os.IsNotExist(err))
+	_, err = ScanSubdirs(filepath.Join(dir, "file.txt"))
+	assert.NotNil(err)
+}
+
+func TestCopySparse(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	src, dst := filepath.Join(dir, "disk.img"), filepath.Join(dir, "out", "disk.img")
+	const size = 16 << 20
+	assert.Nil(PutContents(src, "head"))
+	assert.Nil(TruncateSize(src, size))
+	assert.Nil(WriteAt(src, size/2, []byte("middle")))
+	assert.Nil(WriteAt(src, size-4, []byte("tail")))
+	assert.Nil(os.Chmod(src, 0640))
+
+	allocated := func(p string) int64 {
+		info, err := os.Stat(p)
+		assert.Nil(err)
+		return info.Sys().(*syscall.Stat_t).Blocks * 512
+	}
+	if allocated(src) >= size {
+		t.Skip("filesystem does not support sparse files")
+	}
+
+	assert.Nil(CopySparse(src, dst))
+	same, err := SameContent(src, dst)
+	assert.Nil(err)
+	assert.True(same)
+	assert.Equal(int64(size), Size(dst))
+	assert.True(allocated(dst) < size/2, "destination should be sparse")
+	perm, _ := Perm(dst)
+	assert.Equal(os.FileMode(0640), perm)
+
+	// 末尾为空洞
+	assert.Nil(TruncateSize(src, size*2))
+	assert.Nil(CopySparse(src, dst))
+	assert.Equal(int64(size*2), Size(dst))
+	same, _ = SameContent(src, dst)
+	assert.True(same)
+}
+
+func TestDirEntries(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	assert.Nil(PutContents(filepath.Join(dir, "c.txt"), ""))
+	assert.Nil(Mkdir(filepath.Join(dir, "b")))
+	assert.Nil(os.Symlink("c.txt", filepath.Join(dir, "a")))
+
+	entries, err := DirEntries(dir)
+	assert.Nil(err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.Equal([]string{"a", "b", "c.txt"}, names)
+	assert.Equal(os.ModeSymlink, entries[0].Type())
+	assert.True(entries[1].IsDir())
+	assert.True(entries[2].Type().IsRegular())
+
+	_, err = DirEntries(filepath.Join(dir, "missing"))
+	assert.True(os.IsNotExist(err))
+}
+
+func TestRemoveAllBestEffortReadOnlyDir(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root ignores directory permissions")
+	}
+	assert := assert.New(t)
+	root := filepath.Join(t.TempDir(), "tree")
+	assert.Nil(PutContents(filepath.Join(root, "ro", "keep.txt"), "x"))
+	assert.Nil(PutContents(filepath.Join(root, "rw", "gone.txt"), "x"))
+	assert.Nil(os.Chmod(filepath.Join(root, "ro"), 0555))
+	defer os.Chmod(filepath.Join(root, "ro"), 0755)
+
+	err := RemoveAllBestEffort(root)
+	assert.NotNil(err)
+	assert.True(Exists(filepath.Join(root, "ro", "keep.txt")))
+	assert.False(Exists(filepath.Join(root, "rw")))
+}
+
+func TestCreateMode(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	old := syscall.Umask(077)
+	defer syscall.Umask(old)
+
+	secret := filepath.Join(dir, "secret.key")
+	assert.Nil(CreateMode(secret, 0600, strings.NewReader("key")))
+	perm, err := Perm(secret)
+	assert.Nil(err)
+	assert.Equal(os.FileMode(0600), perm)
+	assert.Equal("key", GetContents(secret))
+
+	script := filepath.Join(dir, "bin", "run.sh")
+	assert.Nil(CreateMode(script, 0755, strings.NewReader("#!/bin/sh\n")))
+	perm, _ = Perm(script)
+	assert.Equal(os.FileMode(0755), perm, "umask must not reduce the mode")
+	assert.True(IsExecutable(script))
+
+	// 已存在的文件被截断并修改权限
+	assert.Nil(CreateMode(secret, 0644))
+	perm, _ = Perm(secret)
+	assert.Equal(os.FileMode(0644), perm)
+	assert.Equal("", GetContents(secret))
+}
+
+func TestCopyOwnership(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("changing ownership requires root")
+	}
+	assert := assert.New(t)
+	dir := t.TempDir()
+	src, dst := filepath.Join(dir, "src"), filepath.Join(dir, "dst")
+	assert.Nil(PutContents(src, "x"))
+	assert.Nil(PutContents(dst, "x"))
+	assert.Nil(Chown(src, 1234, 5678))
+
+	assert.Nil(CopyOwnership(src, dst))
+	info, err := os.Stat(dst)
+	assert.Nil(err)
+	st := info.Sys().(*syscall.Stat_t)
+	assert.Equal(uint32(1234), st.Uid)
+	assert.Equal(uint32(5678), st.Gid)
+
+	assert.NotNil(CopyOwnership(filepath.Join(dir, "missing"), dst))
+}
+
+func TestIsMountPoint(t *testing.T) {
+	assert := assert.New(t)
+	ok, err := IsMountPoint("/")
+	assert.Nil(err)
+	assert.True(ok)
+
+	dir := filepath.Join(t.TempDir(), "sub")
+	assert.Nil(Mkdir(dir))
+	ok, err = IsMountPoint(dir)
+	assert.Nil(err)
+	assert.False(ok)
+
+	_, err = IsMountPoint(filepath.Join(dir, "missing"))
+	assert.True(os.IsNotExist(err))
+}
+
+func TestCopyDirSymlinkMode(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	assert.Nil(PutContents(filepath.Join(src, "a.txt"), "inside"))
+	assert.Nil(PutContents(filepath.Join(src, "sub", "b.txt"), "b"))
+	assert.Nil(PutContents(filepath.Join(dir, "outside.txt"), "outside"))
+	assert.Nil(os.Symlink("../a.txt", filepath.Join(src, "sub", "rel-in")))
+	assert.Nil(os.Symlink(filepath.Join(src, "a.txt"), filepath.Join(src, "abs-in")))
+	assert.Nil(os.Symlink("../outside.txt", filepath.Join(src, "rel-out")))
+	assert.Nil(os.Symlink("sub", filepath.Join(src, "dir-link")))
+
+	// 目标位于不同深度, 检验相对链接的改写
+	dst := filepath.Join(dir, "backup", "deep", "dst")
+	assert.Nil(CopyDir(src, dst))
+	link, err := os.Readlink(filepath.Join(dst, "sub", "rel-in"))
+	assert.Nil(err)
+	assert.Equal("../a.txt", link)
+	link, _ = os.Readlink(filepath.Join(dst, "abs-in"))
+	assert.Equal(filepath.Join(dst, "a.txt"), link)
+	assert.Equal("outside", GetContents(filepath.Join(dst, "rel-out")))
+	assert.True(IsSymlink(filepath.Join(dst, "rel-out")))
+	assert.True(IsSymlink(filepath.Join(dst, "dir-link")))
+
+	dst = filepath.Join(dir, "follow")
+	assert.Nil(CopyDir(src, dst, CopyDirOptions{Symlinks: SymlinkFollow}))
+	for p, want := range map[string]string{"sub/rel-in": "inside", "abs-in": "inside", "rel-out": "outside", "dir-link/b.txt": "b"} {
+		assert.False(IsSymlink(filepath.Join(dst, p)), p)
+		assert.Equal(want, GetContents(filepath.Join(dst, p)), p)
+	}
+	assert.True(IsDir(filepath.Join(dst, "dir-link")))
+
+	dst = filepath.Join(dir, "skip")
+	assert.Nil(CopyDir(src, dst, CopyDirOptions{Symlinks: SymlinkSkip}))
+	assert.Equal([]string{"a.txt", "sub"}, ScanDir(dst))
+	assert.Equal([]string{"b.txt"}, ScanDir(filepath.Join(dst, "sub")))
+
+	// 跟随指向上级目录的链接会形成循环
+	assert.Nil(os.Symlink("..", filepath.Join(src, "sub", "loop")))
+	err = CopyDir(src, filepath.Join(dir, "loop"), CopyDirOptions{Symlinks: SymlinkFollow})
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "symlink cycle")
+}
+
+func TestWriteAtomicFollowsSymlink(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	target := filepath.Join(dir, "real", "config.ini")
+	link := filepath.Join(dir, "config.ini")
+	assert.Nil(PutContents(target, "a=1\n"))
+	assert.Nil(os.Symlink(target, link))
+
+	n, err := ReplaceInFile(link, "a=1", "a=2", true)
+	assert.Nil(err)
+	assert.Equal(1, n)
+	assert.True(IsSymlink(link), "link is kept")
+	assert.Equal("a=2\n", GetContents(target))
+
+	assert.Nil(Edit(link, func(old []byte) ([]byte, error) {
+		return append(old, "b=3\n"...), nil
+	}))
+	assert.Nil(PrependContents(link, "[main]\n"))
+	assert.True(IsSymlink(link))
+	assert.Equal("[main]\na=2\nb=3\n", GetContents(target))
+
+	entries, err := os.ReadDir(dir)
+	assert.Nil(err)
+	assert.Len(entries, 2, "no temp files are left next to the link")
+}
+
+func TestMoveCrossDeviceSymlinkOverFile(t *testing.T) {
+	assert := assert.New(t)
+	crossDeviceRename(t)
+	dir := t.TempDir()
+
+	src := filepath.Join(dir, "link")
+	assert.Nil(os.Symlink("target.txt", src))
+	dst := filepath.Join(dir, "dst.txt")
+	assert.Nil(PutContents(dst, "old"))
+
+	assert.Nil(Move(src, dst))
+	link, err := os.Readlink(dst)
+	assert.Nil(err)
+	assert.Equal("target.txt", link)
+	assert.False(IsSymlink(src))
+}
+
+func TestUnzipReadOnlyDir(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "ro.zip")
+	f, err := os.Create(archive)
+	assert.Nil(err)
+	zw := zip.NewWriter(f)
+	h := &zip.FileHeader{Name: "ro/"}
+	h.SetMode(os.ModeDir | 0555)
+	_, err = zw.CreateHeader(h)
+	assert.Nil(err)
+	h = &zip.FileHeader{Name: "ro/a.txt", Method: zip.Deflate}
+	h.SetMode(0444)
+	w, err := zw.CreateHeader(h)
+	assert.Nil(err)
+	w.Write([]byte("a"))
+	assert.Nil(zw.Close())
+	assert.Nil(f.Close())
+
+	dst := filepath.Join(dir, "out")
+	t.Cleanup(func() { os.Chmod(filepath.Join(dst, "ro"), 0755) })
+	assert.Nil(Unzip(archive, dst))
+	assert.Equal("a", GetContents(filepath.Join(dst, "ro", "a.txt")))
+	info, err := os.Stat(filepath.Join(dst, "ro"))
+	assert.Nil(err)
+	assert.Equal(os.FileMode(0555), info.Mode().Perm())
+	info, err = os.Stat(dst)
+	assert.Nil(err)
+	assert.True(info.Mode().Perm()&0200 != 0, "dstDir stays writable")
+}
+
+func TestUntarRestoresDirMode(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "ro.tar")
+	f, err := os.Create(archive)
+	assert.Nil(err)
+	tw := tar.NewWriter(f)
+	mtime := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.Nil(tw.WriteHeader(&tar.Header{Name: "ro/", Typeflag: tar.TypeDir, Mode: 0555, ModTime: mtime}))
+	assert.Nil(tw.WriteHeader(&tar.Header{Name: "ro/sub/", Typeflag: tar.TypeDir, Mode: 0500, ModTime: mtime}))
+	assert.Nil(tw.WriteHeader(&tar.Header{Name: "ro/sub/a.txt", Typeflag: tar.TypeReg, Mode: 0444, Size: 1, ModTime: mtime}))
+	tw.Write([]byte("a"))
+	assert.Nil(tw.Close())
+	assert.Nil(f.Close())
+
+	dst := filepath.Join(dir, "out")
+	t.Cleanup(func() {
+		os.Chmod(filepath.Join(dst, "ro"), 0755)
+		os.Chmod(filepath.Join(dst, "ro", "sub"), 0755)
+	})
+	assert.Nil(Untar(archive, dst, false))
+	assert.Equal("a", GetContents(filepath.Join(dst, "ro", "sub", "a.txt")))
+	for name, perm := range map[string]os.FileMode{"ro": 0555, filepath.Join("ro", "sub"): 0500} {
+		info, err := os.Stat(filepath.Join(dst, name))
+		assert.Nil(err)
+		assert.Equal(perm, info.Mode().Perm(), name)
+		assert.True(mtime.Equal(info.ModTime()), name)
+	}
+}
+
+func TestCopyDirIntoItselfViaSymlink(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	assert.Nil(PutContents(filepath.Join(src, "a.txt"), "a"))
+	alias := filepath.Join(dir, "alias")
+	assert.Nil(os.Symlink(src, alias))
+
+	assert.True(errors.Is(CopyDir(src, filepath.Join(alias, "backup")), ErrCopyIntoSelf))
+	assert.True(errors.Is(CopyDir(alias, filepath.Join(src, "backup")), ErrCopyIntoSelf))
+	assert.False(Exists(filepath.Join(src, "backup")))
+}
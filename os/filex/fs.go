@@ -0,0 +1,85 @@
+package filex
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// DirFS 返回以 root 为根目录的 fs.FS, 同时实现 fs.ReadDirFS, fs.StatFS, fs.ReadFileFS
+// 路径须符合 fs.ValidPath 规则, 包含 .. 等跳出 root 的路径一律拒绝
+func DirFS(root string) fs.FS {
+	return dirFS(root)
+}
+
+type dirFS string
+
+// join 校验 fs 路径并转换为本地文件路径
+func (d dirFS) join(op, name string) (string, error) {
+	if !fs.ValidPath(name) || runtime.GOOS == "windows" && strings.ContainsAny(name, `\:`) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return filepath.Join(string(d), filepath.FromSlash(name)), nil
+}
+
+// Open 实现 fs.FS
+func (d dirFS) Open(name string) (fs.File, error) {
+	p, err := d.join("open", name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, fsPathError(err, name)
+	}
+	return f, nil
+}
+
+// ReadDir 实现 fs.ReadDirFS
+func (d dirFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	p, err := d.join("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(p)
+	if err != nil {
+		return nil, fsPathError(err, name)
+	}
+	return entries, nil
+}
+
+// Stat 实现 fs.StatFS
+func (d dirFS) Stat(name string) (fs.FileInfo, error) {
+	p, err := d.join("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, fsPathError(err, name)
+	}
+	return info, nil
+}
+
+// ReadFile 实现 fs.ReadFileFS
+func (d dirFS) ReadFile(name string) ([]byte, error) {
+	p, err := d.join("read", name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, fsPathError(err, name)
+	}
+	return data, nil
+}
+
+// fsPathError 将错误中的本地路径替换为 fs 路径, 避免泄露 root
+func fsPathError(err error, name string) error {
+	if pe, ok := err.(*fs.PathError); ok {
+		pe.Path = name
+	}
+	return err
+}
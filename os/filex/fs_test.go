@@ -0,0 +1,35 @@
+package filex
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirFS(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	assert.Nil(PutContents(filepath.Join(dir, "a.txt"), "a"))
+	assert.Nil(PutContents(filepath.Join(dir, "sub", "b.txt"), "bb"))
+	assert.Nil(PutContents(filepath.Join(dir, "sub", "deep", "c.txt"), "ccc"))
+	assert.Nil(Mkdir(filepath.Join(dir, "empty")))
+
+	fsys := DirFS(dir)
+	assert.Nil(fstest.TestFS(fsys, "a.txt", "sub/b.txt", "sub/deep/c.txt", "empty"))
+
+	data, err := fs.ReadFile(fsys, "sub/b.txt")
+	assert.Nil(err)
+	assert.Equal("bb", string(data))
+
+	for _, name := range []string{"../a.txt", "sub/../../a.txt", "/etc/passwd"} {
+		_, err = fsys.Open(name)
+		assert.True(errors.Is(err, fs.ErrInvalid), name)
+	}
+	_, err = fs.Stat(fsys, "missing.txt")
+	assert.True(errors.Is(err, fs.ErrNotExist))
+	assert.Equal("missing.txt", err.(*fs.PathError).Path)
+}
@@ -0,0 +1,78 @@
+package filex
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"os"
+)
+
+// Gzip 将 src 文件压缩为 gzip 格式写入 dst, 自动创建 dst 所在目录
+// 压缩文件沿用源文件的权限, 并在 gzip 头中记录源文件名及修改时间
+func Gzip(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	fi, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	return writeStream(dst, fi.Mode().Perm(), func(w io.Writer) error {
+		zw := gzip.NewWriter(w)
+		zw.Name = fi.Name()
+		zw.ModTime = fi.ModTime()
+		if _, err := io.CopyBuffer(zw, in, make([]byte, copyBufferSize)); err != nil {
+			return err
+		}
+		return zw.Close()
+	})
+}
+
+// Gunzip 将 gzip 格式的 src 文件解压写入 dst, 自动创建 dst 所在目录
+// 解压文件沿用压缩文件的权限
+func Gunzip(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	fi, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	zr, err := gzip.NewReader(bufio.NewReader(in))
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	return writeStream(dst, fi.Mode().Perm(), func(w io.Writer) error {
+		_, err := io.CopyBuffer(w, zr, make([]byte, copyBufferSize))
+		return err
+	})
+}
+
+// writeStream 创建(截断) path 并通过 write 写入内容, 失败时删除未完成的文件
+func writeStream(path string, perm os.FileMode, write func(w io.Writer) error) error {
+	if err := mkdirParent(path); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriterSize(f, copyBufferSize)
+	err = write(bw)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package filex
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGzipRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+
+	bin := make([]byte, 256*1024)
+	rand.New(rand.NewSource(1)).Read(bin)
+	text := []byte(strings.Repeat("log line 日志\n", 5000))
+
+	for name, content := range map[string][]byte{"bin": bin, "text": text} {
+		src := filepath.Join(dir, name)
+		gz := filepath.Join(dir, "gz", name+".gz")
+		out := filepath.Join(dir, "out", name)
+		assert.Nil(PutBinContents(src, content))
+		assert.Nil(Gzip(src, gz))
+
+		f, err := os.Open(gz)
+		assert.Nil(err)
+		zr, err := gzip.NewReader(f)
+		assert.Nil(err)
+		assert.Equal(name, zr.Name)
+		data, err := io.ReadAll(zr)
+		assert.Nil(err)
+		assert.True(bytes.Equal(content, data))
+		f.Close()
+
+		assert.Nil(Gunzip(gz, out))
+		assert.True(bytes.Equal(content, GetBinContents(out)))
+	}
+
+	notgz := filepath.Join(dir, "text")
+	assert.NotNil(Gunzip(notgz, filepath.Join(dir, "bad")))
+	assert.False(Exists(filepath.Join(dir, "bad")))
+}
@@ -0,0 +1,12 @@
+//go:build unix
+
+package filex
+
+import (
+	"io/fs"
+	"strings"
+)
+
+func isHidden(e fs.DirEntry) bool {
+	return strings.HasPrefix(e.Name(), ".")
+}
@@ -0,0 +1,21 @@
+package filex
+
+import (
+	"io/fs"
+	"strings"
+	"syscall"
+)
+
+func isHidden(e fs.DirEntry) bool {
+	if strings.HasPrefix(e.Name(), ".") {
+		return true
+	}
+	info, err := e.Info()
+	if err != nil {
+		return false
+	}
+	if attr, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
+		return attr.FileAttributes&syscall.FILE_ATTRIBUTE_HIDDEN != 0
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+package filex
+
+import (
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListOptionsHiddenAttribute(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	hidden := filepath.Join(dir, "desktop.ini")
+	assert.Nil(PutContents(hidden, ""))
+	assert.Nil(PutContents(filepath.Join(dir, "visible.txt"), ""))
+	p, err := syscall.UTF16PtrFromString(hidden)
+	assert.Nil(err)
+	assert.Nil(syscall.SetFileAttributes(p, syscall.FILE_ATTRIBUTE_HIDDEN))
+
+	assert.Equal([]string{"desktop.ini", "visible.txt"}, ScanDir(dir))
+	assert.Equal([]string{"desktop.ini", "visible.txt"}, ScanDir(dir, ListOptions{}))
+	assert.Equal([]string{"visible.txt"}, ScanDir(dir, ListOptions{SkipHidden: true}))
+}
@@ -0,0 +1,350 @@
+package filex
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// tailChunkSize Tail 从文件末尾向前读取的块大小
+const tailChunkSize = 4096
+
+// Head 读取文件前 n 行, 读够 n 行后即停止读取, 返回的行不含换行符
+func Head(path string, n int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var lines []string
+	if n <= 0 {
+		return lines, nil
+	}
+	err = eachLine(f, func(line string) bool {
+		lines = append(lines, line)
+		return len(lines) < n
+	})
+	if err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// Tail 读取文件最后 n 行, 从文件末尾分块向前读取, 不会读取整个文件, 返回的行不含换行符
+func Tail(path string, n int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return tailLines(f, fi.Size(), n)
+}
+
+func tailLines(r io.ReaderAt, size int64, n int) ([]string, error) {
+	if n <= 0 || size == 0 {
+		return nil, nil
+	}
+	off, err := tailOffset(r, size, n)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, size-off)
+	if _, err := r.ReadAt(buf, off); err != nil && err != io.EOF {
+		return nil, err
+	}
+	lines := strings.Split(strings.TrimSuffix(string(buf), "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines, nil
+}
+
+// tailOffset 从末尾分块向前查找, 返回最后 n 行起始位置的偏移量, 不足 n 行时返回 0
+// 末尾换行符不算作新的一行, 需要 n 个换行符才能确定最后 n 行的起点
+func tailOffset(r io.ReaderAt, size int64, n int) (int64, error) {
+	if n <= 0 {
+		return size, nil
+	}
+	buf := make([]byte, tailChunkSize)
+	count := 0
+	for pos := size; pos > 0; {
+		chunk := int64(tailChunkSize)
+		if chunk > pos {
+			chunk = pos
+		}
+		pos -= chunk
+		b := buf[:chunk]
+		if _, err := r.ReadAt(b, pos); err != nil && err != io.EOF {
+			return 0, err
+		}
+		for i := len(b) - 1; i >= 0; i-- {
+			if b[i] != '\n' || pos+int64(i) == size-1 {
+				continue
+			}
+			if count++; count == n {
+				return pos + int64(i) + 1, nil
+			}
+		}
+	}
+	return 0, nil
+}
+
+// TailTruncate 只保留文件最后 keepLines 行, 通过临时文件原子写回
+// 从文件末尾向前查找保留部分的起点(同 Tail), 不会读取整个文件; 行数不超过 keepLines 时不会重写文件
+func TailTruncate(path string, keepLines int) error {
+	if keepLines < 0 {
+		return errors.New("tail truncate: negative line count")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	off, err := tailOffset(f, fi.Size(), keepLines)
+	f.Close()
+	if err != nil || off == 0 {
+		return err
+	}
+	return writeFileAtomic(path, 0666, func(w io.Writer) error {
+		// 重命名替换前关闭源文件(Windows 下无法替换已打开的文件)
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if _, err := f.Seek(off, io.SeekStart); err != nil {
+			return err
+		}
+		_, err = CopyTo(w, f)
+		return err
+	})
+}
+
+// trimEOL 去除行尾的 \n 或 \r\n
+func trimEOL(line string) string {
+	line = strings.TrimSuffix(line, "\n")
+	return strings.TrimSuffix(line, "\r")
+}
+
+// ErrLineOutOfRange 指定的行号超出文件行数
+var ErrLineOutOfRange = errors.New("line out of range")
+
+// ReadLine 读取文件第 n 行(从 1 开始, 不含换行符), 读到该行即停止读取
+// 文件行数不足 n 行或 n 小于 1 时返回 ErrLineOutOfRange
+func ReadLine(path string, n int) (string, error) {
+	if n < 1 {
+		return "", ErrLineOutOfRange
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	var result string
+	found := false
+	i := 0
+	err = eachLine(f, func(line string) bool {
+		i++
+		if i == n {
+			result, found = line, true
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", ErrLineOutOfRange
+	}
+	return result, nil
+}
+
+// GrepMatch 匹配行
+type GrepMatch struct {
+	// Line 行号, 从 1 开始
+	Line int
+	// Text 行内容, 不含换行符
+	Text string
+}
+
+// Grep 流式逐行查找文件中匹配 pattern 的行
+func Grep(path string, pattern *regexp.Regexp) ([]GrepMatch, error) {
+	var matches []GrepMatch
+	err := GrepFunc(path, pattern, func(m GrepMatch) bool {
+		matches = append(matches, m)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+// GrepFunc 流式逐行查找文件中匹配 pattern 的行, 每个匹配行调用一次 fn, fn 返回 false 时停止查找
+func GrepFunc(path string, pattern *regexp.Regexp, fn func(m GrepMatch) bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	n := 0
+	return eachLine(f, func(line string) bool {
+		n++
+		if pattern.MatchString(line) {
+			return fn(GrepMatch{Line: n, Text: line})
+		}
+		return true
+	})
+}
+
+// eachLine 逐行读取 r, 对每一行(不含换行符)调用 fn, fn 返回 false 时停止读取
+func eachLine(r io.Reader, fn func(line string) bool) error {
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if line != "" && !fn(trimEOL(line)) {
+			return nil
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// FilterLines 流式逐行过滤文件, 只保留 keep 返回 true 的行(不含换行符), 结果原子写回文件, 返回删除的行数
+// 保留行的换行符(\n 或 \r\n)不变; 原文件末尾没有换行符时结果末尾也不会有换行符
+func FilterLines(path string, keep func(line string) bool) (int, error) {
+	removed := 0
+	err := rewriteFile(path, func(r *bufio.Reader, w io.Writer) error {
+		// 上一保留行的换行符, 写入下一保留行前才输出, 以便保持末尾换行的约定
+		var pending string
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil && err != io.EOF {
+				return err
+			}
+			if line != "" {
+				content := trimEOL(line)
+				if !keep(content) {
+					removed++
+				} else {
+					if _, err := io.WriteString(w, pending+content); err != nil {
+						return err
+					}
+					pending = line[len(content):]
+				}
+			}
+			if err == io.EOF {
+				// 原文件以换行符结尾时补上最后一个保留行的换行符
+				if line == "" && pending != "" {
+					_, err := io.WriteString(w, pending)
+					return err
+				}
+				return nil
+			}
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
+
+// MapLines 流式逐行转换文件, 每行(不含换行符)替换为 transform 的返回值, 结果原子写回文件
+// 每行原有的换行符(\n 或 \r\n)保持不变; transform 返回空字符串时输出空行, 不会删除该行
+func MapLines(path string, transform func(line string) string) error {
+	return rewriteFile(path, func(r *bufio.Reader, w io.Writer) error {
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil && err != io.EOF {
+				return err
+			}
+			if line != "" {
+				content := trimEOL(line)
+				if _, err := io.WriteString(w, transform(content)+line[len(content):]); err != nil {
+					return err
+				}
+			}
+			if err == io.EOF {
+				return nil
+			}
+		}
+	})
+}
+
+// rewriteFile 流式读取 path 并通过 rewrite 生成新内容, 最后原子替换 path
+// 源文件在 rewrite 返回后、重命名替换前关闭(Windows 下无法替换已打开的文件)
+func rewriteFile(path string, rewrite func(r *bufio.Reader, w io.Writer) error) error {
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, 0666, func(w io.Writer) error {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return rewrite(bufio.NewReader(f), w)
+	})
+}
+
+// AppendUnique 文件中不存在与 line 完全相同的行时, 将 line 及换行符 \n 追加到文件末尾, 返回是否追加
+// 流式逐行比较(忽略行尾的 \r\n 差异); 文件不存在时创建; 原文件末尾没有换行符时先补上换行符
+func AppendUnique(path, line string) (appended bool, err error) {
+	f, err := os.Open(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	needEOL := false
+	if f != nil {
+		found := false
+		var last string
+		err := eachLine(f, func(l string) bool {
+			found = l == line
+			last = l
+			return !found
+		})
+		if err == nil && !found && last != "" {
+			needEOL, err = missingEOL(f)
+		}
+		f.Close()
+		if err != nil || found {
+			return false, err
+		}
+	}
+	if needEOL {
+		line = "\n" + line
+	}
+	if err := AppendContents(path, line+"\n"); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// missingEOL 判断非空文件是否不以换行符结尾
+func missingEOL(f *os.File) (bool, error) {
+	info, err := f.Stat()
+	if err != nil || info.Size() == 0 {
+		return false, err
+	}
+	b := make([]byte, 1)
+	if _, err := f.ReadAt(b, info.Size()-1); err != nil {
+		return false, err
+	}
+	return b[0] != '\n', nil
+}
@@ -0,0 +1,271 @@
+package filex
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingReaderAt 记录读取过的最小偏移
+type countingReaderAt struct {
+	r      io.ReaderAt
+	minOff int64
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < c.minOff {
+		c.minOff = off
+	}
+	return c.r.ReadAt(p, off)
+}
+
+func TestHeadTail(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+
+	p := filepath.Join(dir, "log.txt")
+	assert.Nil(PutContents(p, "one\ntwo\r\nthree\nfour\n"))
+	lines, err := Head(p, 2)
+	assert.Nil(err)
+	assert.Equal([]string{"one", "two"}, lines)
+	lines, err = Tail(p, 2)
+	assert.Nil(err)
+	assert.Equal([]string{"three", "four"}, lines)
+	lines, err = Head(p, 10)
+	assert.Nil(err)
+	assert.Equal([]string{"one", "two", "three", "four"}, lines)
+	lines, err = Tail(p, 10)
+	assert.Nil(err)
+	assert.Equal([]string{"one", "two", "three", "four"}, lines)
+
+	noEOL := filepath.Join(dir, "noeol.txt")
+	assert.Nil(PutContents(noEOL, "a\nb\nc"))
+	lines, err = Tail(noEOL, 2)
+	assert.Nil(err)
+	assert.Equal([]string{"b", "c"}, lines)
+	lines, err = Head(noEOL, 5)
+	assert.Nil(err)
+	assert.Equal([]string{"a", "b", "c"}, lines)
+
+	empty := filepath.Join(dir, "empty.txt")
+	assert.Nil(Create(empty))
+	lines, err = Head(empty, 3)
+	assert.Nil(err)
+	assert.Empty(lines)
+	lines, err = Tail(empty, 3)
+	assert.Nil(err)
+	assert.Empty(lines)
+
+	_, err = Tail(filepath.Join(dir, "missing"), 1)
+	assert.NotNil(err)
+}
+
+func TestTailReadsOnlyEnd(t *testing.T) {
+	assert := assert.New(t)
+	var b strings.Builder
+	for i := 0; i < 100000; i++ {
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	data := []byte(b.String())
+	r := &countingReaderAt{r: bytes.NewReader(data), minOff: int64(len(data))}
+	lines, err := tailLines(r, int64(len(data)), 3)
+	assert.Nil(err)
+	assert.Equal([]string{"line 99997", "line 99998", "line 99999"}, lines)
+	assert.True(int64(len(data))-r.minOff <= tailChunkSize, "only the last chunk should be read")
+}
+
+func TestAppendLines(t *testing.T) {
+	assert := assert.New(t)
+	p := filepath.Join(t.TempDir(), "logs", "app.log")
+
+	assert.Nil(AppendLine(p, "first"))
+	assert.Nil(AppendLines(p, []string{"second", "third"}))
+	assert.Nil(AppendLines(p, nil))
+	assert.Equal("first\nsecond\nthird\n", GetContents(p))
+
+	assert.Nil(Truncate(p, 0))
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				line := fmt.Sprintf("g%d-%03d-%s", g, i, strings.Repeat("x", 100))
+				assert.Nil(AppendLines(p, []string{line, line}))
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	lines, err := Head(p, 10000)
+	assert.Nil(err)
+	assert.Len(lines, 1600)
+	for i := 0; i < len(lines); i += 2 {
+		assert.Len(lines[i], 107)
+		assert.Equal(lines[i], lines[i+1], "pairs written together must stay adjacent")
+	}
+}
+
+func TestGrep(t *testing.T) {
+	assert := assert.New(t)
+	p := filepath.Join(t.TempDir(), "app.log")
+	assert.Nil(PutContents(p, "INFO start\nERROR disk full\nINFO retry\nERROR disk full again\nDONE"))
+
+	matches, err := Grep(p, regexp.MustCompile(`^ERROR`))
+	assert.Nil(err)
+	assert.Equal([]GrepMatch{{Line: 2, Text: "ERROR disk full"}, {Line: 4, Text: "ERROR disk full again"}}, matches)
+
+	matches, err = Grep(p, regexp.MustCompile(`^DONE$`))
+	assert.Nil(err)
+	assert.Equal([]GrepMatch{{Line: 5, Text: "DONE"}}, matches)
+
+	matches, err = Grep(p, regexp.MustCompile(`WARN`))
+	assert.Nil(err)
+	assert.Empty(matches)
+
+	var first []GrepMatch
+	err = GrepFunc(p, regexp.MustCompile(`INFO`), func(m GrepMatch) bool {
+		first = append(first, m)
+		return false
+	})
+	assert.Nil(err)
+	assert.Equal([]GrepMatch{{Line: 1, Text: "INFO start"}}, first)
+
+	_, err = Grep(p+".missing", regexp.MustCompile(`x`))
+	assert.NotNil(err)
+}
+
+func TestFilterLines(t *testing.T) {
+	assert := assert.New(t)
+	p := filepath.Join(t.TempDir(), "f.txt")
+
+	n := 0
+	assert.Nil(PutContents(p, "1\n2\r\n3\n4\n5"))
+	removed, err := FilterLines(p, func(line string) bool {
+		n++
+		return n%2 == 1
+	})
+	assert.Nil(err)
+	assert.Equal(2, removed)
+	assert.Equal("1\n3\n5", GetContents(p))
+
+	// 删除了没有换行符的最后一行, 结果末尾同样不带换行符
+	assert.Nil(PutContents(p, "# c\nkeep\r\n# tail"))
+	removed, err = FilterLines(p, func(line string) bool { return !strings.HasPrefix(line, "#") })
+	assert.Nil(err)
+	assert.Equal(2, removed)
+	assert.Equal("keep", GetContents(p))
+
+	assert.Nil(PutContents(p, "a\nb\n"))
+	removed, err = FilterLines(p, func(line string) bool { return line == "a" })
+	assert.Nil(err)
+	assert.Equal(1, removed)
+	assert.Equal("a\n", GetContents(p))
+
+	removed, err = FilterLines(p, func(string) bool { return false })
+	assert.Nil(err)
+	assert.Equal(1, removed)
+	assert.Equal("", GetContents(p))
+	assert.True(Exists(p))
+}
+
+func TestMapLines(t *testing.T) {
+	assert := assert.New(t)
+	p := filepath.Join(t.TempDir(), "f.txt")
+
+	assert.Nil(PutContents(p, "a  \r\n\tb\t\r\n  \r\nc "))
+	assert.Nil(MapLines(p, func(line string) string { return strings.TrimRight(line, " \t") }))
+	assert.Equal("a\r\n\tb\r\n\r\nc", GetContents(p))
+
+	assert.Nil(PutContents(p, "one\ntwo\n"))
+	assert.Nil(MapLines(p, strings.ToUpper))
+	assert.Equal("ONE\nTWO\n", GetContents(p))
+
+	assert.Nil(MapLines(p, func(string) string { return "" }))
+	assert.Equal("\n\n", GetContents(p))
+
+	assert.NotNil(MapLines(filepath.Join(t.TempDir(), "missing"), strings.ToUpper))
+}
+
+func TestReadLine(t *testing.T) {
+	assert := assert.New(t)
+	p := filepath.Join(t.TempDir(), "f.txt")
+	assert.Nil(PutContents(p, "first\r\nsecond\n\nfourth"))
+
+	for n, want := range map[int]string{1: "first", 2: "second", 3: "", 4: "fourth"} {
+		line, err := ReadLine(p, n)
+		assert.Nil(err)
+		assert.Equal(want, line, "line %d", n)
+	}
+	for _, n := range []int{0, -1, 5} {
+		_, err := ReadLine(p, n)
+		assert.Equal(ErrLineOutOfRange, err)
+	}
+
+	assert.Nil(PutContents(p, "only\n"))
+	_, err := ReadLine(p, 2)
+	assert.Equal(ErrLineOutOfRange, err)
+}
+
+func TestAppendUnique(t *testing.T) {
+	assert := assert.New(t)
+	p := filepath.Join(t.TempDir(), "hosts")
+
+	ok, err := AppendUnique(p, "127.0.0.1 localhost")
+	assert.Nil(err)
+	assert.True(ok)
+	ok, err = AppendUnique(p, "127.0.0.1 localhost")
+	assert.Nil(err)
+	assert.False(ok)
+	assert.Equal("127.0.0.1 localhost\n", GetContents(p))
+
+	// 只匹配完整的行
+	ok, err = AppendUnique(p, "127.0.0.1")
+	assert.Nil(err)
+	assert.True(ok)
+	ok, _ = AppendUnique(p, "localhost")
+	assert.True(ok)
+	assert.Equal("127.0.0.1 localhost\n127.0.0.1\nlocalhost\n", GetContents(p))
+
+	assert.Nil(PutContents(p, "export A=1\r\nexport B=2"))
+	ok, _ = AppendUnique(p, "export A=1")
+	assert.False(ok)
+	ok, _ = AppendUnique(p, "export C=3")
+	assert.True(ok)
+	assert.Equal("export A=1\r\nexport B=2\nexport C=3\n", GetContents(p))
+}
+
+func TestTailTruncate(t *testing.T) {
+	assert := assert.New(t)
+	p := filepath.Join(t.TempDir(), "app.log")
+
+	var sb strings.Builder
+	for i := 1; i <= 2000; i++ {
+		fmt.Fprintf(&sb, "log line %d\r\n", i)
+	}
+	assert.Nil(PutContents(p, sb.String()))
+	assert.Nil(TailTruncate(p, 3))
+	assert.Equal("log line 1998\r\nlog line 1999\r\nlog line 2000\r\n", GetContents(p))
+
+	// 行数恰好或不足时内容不变
+	assert.Nil(TailTruncate(p, 3))
+	assert.Equal("log line 1998\r\nlog line 1999\r\nlog line 2000\r\n", GetContents(p))
+	assert.Nil(TailTruncate(p, 10))
+	assert.Equal("log line 1998\r\nlog line 1999\r\nlog line 2000\r\n", GetContents(p))
+
+	assert.Nil(PutContents(p, "a\nb\nc"))
+	assert.Nil(TailTruncate(p, 2))
+	assert.Equal("b\nc", GetContents(p))
+	assert.Nil(TailTruncate(p, 0))
+	assert.Equal("", GetContents(p))
+
+	assert.NotNil(TailTruncate(p, -1))
+	assert.NotNil(TailTruncate(filepath.Join(t.TempDir(), "missing"), 1))
+}
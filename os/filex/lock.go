@@ -0,0 +1,71 @@
+package filex
+
+import (
+	"errors"
+	"os"
+)
+
+// ErrLocked 文件已被其他进程或句柄锁定
+var ErrLocked = errors.New("file is locked")
+
+// LockSuffix 锁文件后缀
+const LockSuffix = ".lock"
+
+// FileLock 文件互斥锁(建议锁)
+// 锁加在 path + LockSuffix 伴随文件上, 而不是目标文件本身,
+// 这样目标文件被原子替换(rename)后锁依然有效; 解锁后不会删除锁文件
+type FileLock struct {
+	path string
+	f    *os.File
+}
+
+// Lock 获取指定文件的互斥锁, 锁被占用时阻塞等待
+func Lock(path string) (*FileLock, error) {
+	return acquireLock(path, true)
+}
+
+// TryLock 尝试获取指定文件的互斥锁, 锁被占用时立即返回 ErrLocked
+func TryLock(path string) (*FileLock, error) {
+	return acquireLock(path, false)
+}
+
+// WithLock 在持有指定文件互斥锁期间执行 fn
+func WithLock(path string, fn func() error) error {
+	l, err := Lock(path)
+	if err != nil {
+		return err
+	}
+	defer l.Unlock()
+	return fn()
+}
+
+func acquireLock(path string, block bool) (*FileLock, error) {
+	name := path + LockSuffix
+	f, err := Open(name, os.O_RDWR|os.O_CREATE)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f, block); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &FileLock{path: name, f: f}, nil
+}
+
+// Path 锁文件路径
+func (l *FileLock) Path() string {
+	return l.path
+}
+
+// Unlock 释放锁
+func (l *FileLock) Unlock() error {
+	if l.f == nil {
+		return nil
+	}
+	err := unlockFile(l.f)
+	if cerr := l.f.Close(); err == nil {
+		err = cerr
+	}
+	l.f = nil
+	return err
+}
@@ -0,0 +1,16 @@
+//go:build !windows && !(unix && !aix && (!solaris || illumos))
+
+package filex
+
+import (
+	"errors"
+	"os"
+)
+
+func lockFile(f *os.File, block bool) error {
+	return errors.New("file lock is not supported on this platform")
+}
+
+func unlockFile(f *os.File) error {
+	return errors.New("file lock is not supported on this platform")
+}
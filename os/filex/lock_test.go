@@ -0,0 +1,95 @@
+package filex
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockMutualExclusion(t *testing.T) {
+	assert := assert.New(t)
+	path := filepath.Join(t.TempDir(), "data.txt")
+
+	var holders, maxHolders int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := WithLock(path, func() error {
+				n := atomic.AddInt32(&holders, 1)
+				for {
+					m := atomic.LoadInt32(&maxHolders)
+					if n <= m || atomic.CompareAndSwapInt32(&maxHolders, m, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				atomic.AddInt32(&holders, -1)
+				return nil
+			})
+			assert.Nil(err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(int32(1), maxHolders)
+}
+
+func TestTryLock(t *testing.T) {
+	assert := assert.New(t)
+	path := filepath.Join(t.TempDir(), "data.txt")
+
+	l, err := Lock(path)
+	assert.Nil(err)
+	_, err = TryLock(path)
+	assert.Equal(ErrLocked, err)
+
+	assert.Nil(l.Unlock())
+	l2, err := TryLock(path)
+	assert.Nil(err)
+	assert.Nil(l2.Unlock())
+}
+
+func TestLockHelperProcess(t *testing.T) {
+	path := os.Getenv("GOX_FILEX_LOCK_PATH")
+	if path == "" {
+		t.Skip("helper process only")
+	}
+	l, err := TryLock(path)
+	if err == ErrLocked {
+		os.Exit(3)
+	}
+	if err != nil {
+		os.Exit(1)
+	}
+	l.Unlock()
+	os.Exit(0)
+}
+
+func TestLockAcrossProcesses(t *testing.T) {
+	assert := assert.New(t)
+	path := filepath.Join(t.TempDir(), "data.txt")
+
+	run := func() int {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestLockHelperProcess$")
+		cmd.Env = append(os.Environ(), "GOX_FILEX_LOCK_PATH="+path)
+		err := cmd.Run()
+		if e, ok := err.(*exec.ExitError); ok {
+			return e.ExitCode()
+		}
+		assert.Nil(err)
+		return 0
+	}
+
+	l, err := Lock(path)
+	assert.Nil(err)
+	assert.Equal(3, run(), "subprocess should see the lock held")
+	assert.Nil(l.Unlock())
+	assert.Equal(0, run(), "subprocess should acquire the released lock")
+}
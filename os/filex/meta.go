@@ -0,0 +1,42 @@
+package filex
+
+import "os"
+
+// MetaSuffix 元数据附属文件后缀
+const MetaSuffix = ".meta.json"
+
+// MetaPath 返回文件的元数据附属文件路径, 即 path + MetaSuffix
+func MetaPath(path string) string {
+	return path + MetaSuffix
+}
+
+// SetMeta 将键值元数据以 JSON 格式原子写入文件旁的附属文件(见 MetaPath), 覆盖已有的元数据
+func SetMeta(path string, meta map[string]string) error {
+	if meta == nil {
+		meta = map[string]string{}
+	}
+	return WriteJSON(MetaPath(path), meta, true)
+}
+
+// GetMeta 读取文件的键值元数据, 附属文件不存在时返回空 map
+func GetMeta(path string) (map[string]string, error) {
+	meta := map[string]string{}
+	if err := ReadJSON(MetaPath(path), &meta); err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+	if meta == nil {
+		meta = map[string]string{}
+	}
+	return meta, nil
+}
+
+// moveMeta 移动文件的元数据附属文件(如存在)
+func moveMeta(src, dst string) error {
+	if _, err := os.Lstat(MetaPath(src)); os.IsNotExist(err) {
+		return nil
+	}
+	return Move(MetaPath(src), MetaPath(dst))
+}
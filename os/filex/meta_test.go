@@ -0,0 +1,55 @@
+package filex
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMeta(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	p := filepath.Join(dir, "logo.png")
+	assert.Nil(PutContents(p, "png"))
+
+	meta, err := GetMeta(p)
+	assert.Nil(err)
+	assert.NotNil(meta)
+	assert.Empty(meta)
+
+	assert.Nil(SetMeta(p, map[string]string{"author": "alice", "license": "MIT"}))
+	assert.True(Exists(p + ".meta.json"))
+	meta, err = GetMeta(p)
+	assert.Nil(err)
+	assert.Equal(map[string]string{"author": "alice", "license": "MIT"}, meta)
+
+	assert.Nil(SetMeta(p, map[string]string{"author": "bob"}))
+	meta, _ = GetMeta(p)
+	assert.Equal(map[string]string{"author": "bob"}, meta)
+
+	assert.Nil(PutContents(MetaPath(p), "{broken"))
+	_, err = GetMeta(p)
+	assert.NotNil(err)
+}
+
+func TestMoveWithMeta(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	src, dst := filepath.Join(dir, "a.png"), filepath.Join(dir, "sub", "b.png")
+	assert.Nil(PutContents(src, "png"))
+	assert.Nil(SetMeta(src, map[string]string{"k": "v"}))
+
+	assert.Nil(MoveWithOptions(src, dst, MoveOptions{Meta: true}))
+	assert.False(Exists(MetaPath(src)))
+	meta, err := GetMeta(dst)
+	assert.Nil(err)
+	assert.Equal(map[string]string{"k": "v"}, meta)
+
+	// 没有附属文件时正常移动
+	other := filepath.Join(dir, "c.png")
+	assert.Nil(MoveWithOptions(dst, other, MoveOptions{}))
+	assert.True(Exists(MetaPath(dst)), "meta stays without the Meta option")
+	assert.Nil(MoveWithOptions(other, dst, MoveOptions{Overwrite: true, Meta: true}))
+	assert.True(Exists(dst))
+}
@@ -0,0 +1,12 @@
+package filex
+
+// GetContentsMmap 以只读内存映射方式读取文件内容, 避免大文件整体复制到堆内存
+// 返回映射的字节切片及解除映射函数, 解除映射后切片不可再访问, 否则程序会崩溃
+// 映射期间切片只读, 写入同样会导致程序崩溃
+func GetContentsMmap(path string) ([]byte, func() error, error) {
+	return mmapFile(path)
+}
+
+func noopUnmap() error {
+	return nil
+}
@@ -0,0 +1,9 @@
+//go:build !unix && !windows
+
+package filex
+
+import "errors"
+
+func mmapFile(path string) ([]byte, func() error, error) {
+	return nil, nil, errors.New("mmap is not supported on this platform")
+}
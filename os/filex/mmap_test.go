@@ -0,0 +1,36 @@
+package filex
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetContentsMmap(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "big.bin")
+	content := bytes.Repeat([]byte("0123456789abcdef"), 64*1024)
+	assert.Nil(PutBinContents(path, content))
+
+	data, unmap, err := GetContentsMmap(path)
+	assert.Nil(err)
+	assert.Equal(len(content), len(data))
+	assert.True(bytes.Equal(content, data))
+	assert.Equal(1024*64, bytes.Count(data, []byte("f")))
+	assert.Nil(unmap())
+	assert.Nil(unmap(), "unmap twice should be harmless")
+
+	empty := filepath.Join(dir, "empty.bin")
+	assert.Nil(Create(empty))
+	data, unmap, err = GetContentsMmap(empty)
+	assert.Nil(err)
+	assert.Len(data, 0)
+	assert.Nil(unmap())
+
+	_, _, err = GetContentsMmap(filepath.Join(dir, "missing"))
+	assert.NotNil(err)
+}
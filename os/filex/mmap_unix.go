@@ -0,0 +1,41 @@
+//go:build unix
+
+package filex
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+func mmapFile(path string) ([]byte, func() error, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	size := fi.Size()
+	if size == 0 {
+		return []byte{}, noopUnmap, nil
+	}
+	if int64(int(size)) != size {
+		return nil, nil, errors.New("file is too large to mmap")
+	}
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+	unmap := func() error {
+		if data == nil {
+			return nil
+		}
+		err := syscall.Munmap(data)
+		data = nil
+		return err
+	}
+	return data, unmap, nil
+}
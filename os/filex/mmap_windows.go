@@ -0,0 +1,46 @@
+package filex
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+func mmapFile(path string) ([]byte, func() error, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	size := fi.Size()
+	if size == 0 {
+		return []byte{}, noopUnmap, nil
+	}
+	if int64(int(size)) != size {
+		return nil, nil, errors.New("file is too large to mmap")
+	}
+	h, err := syscall.CreateFileMapping(syscall.Handle(f.Fd()), nil, syscall.PAGE_READONLY, 0, 0, nil)
+	if err != nil {
+		return nil, nil, os.NewSyscallError("CreateFileMapping", err)
+	}
+	addr, err := syscall.MapViewOfFile(h, syscall.FILE_MAP_READ, 0, 0, uintptr(size))
+	syscall.CloseHandle(h)
+	if err != nil {
+		return nil, nil, os.NewSyscallError("MapViewOfFile", err)
+	}
+	data := unsafe.Slice(*(**byte)(unsafe.Pointer(&addr)), int(size))
+	unmap := func() error {
+		if addr == 0 {
+			return nil
+		}
+		err := syscall.UnmapViewOfFile(addr)
+		addr = 0
+		return err
+	}
+	return data, unmap, nil
+}
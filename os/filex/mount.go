@@ -0,0 +1,7 @@
+package filex
+
+// IsMountPoint 判断目录是否为挂载点(另一文件系统挂载于此), 文件系统根目录视为挂载点
+// Unix 下比较目录与其上级目录的设备号; Windows 下判断路径是否为卷根目录或卷挂载点
+func IsMountPoint(path string) (bool, error) {
+	return isMountPoint(path)
+}
@@ -0,0 +1,14 @@
+//go:build !unix && !windows
+
+package filex
+
+import "errors"
+
+func isMountPoint(path string) (bool, error) {
+	return false, errors.New("mount point detection is not supported on this platform")
+}
+
+// fileDevice 与 Windows 相同, 所有路径视为同一设备
+func fileDevice(path string) (uint64, error) {
+	return 0, nil
+}
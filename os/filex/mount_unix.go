@@ -0,0 +1,42 @@
+//go:build unix
+
+package filex
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+func isMountPoint(path string) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	parent, err := os.Stat(filepath.Join(path, ".."))
+	if err != nil {
+		return false, err
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	pst, pok := parent.Sys().(*syscall.Stat_t)
+	if !ok || !pok {
+		return false, &os.PathError{Op: "ismountpoint", Path: path, Err: syscall.ENOTSUP}
+	}
+	if st.Dev != pst.Dev {
+		return true, nil
+	}
+	// 根目录的上级目录是其自身
+	return st.Ino == pst.Ino, nil
+}
+
+func fileDevice(path string) (uint64, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return 0, err
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, &os.PathError{Op: "lstat", Path: path, Err: syscall.ENOTSUP}
+	}
+	return uint64(st.Dev), nil
+}
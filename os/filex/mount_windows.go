@@ -0,0 +1,41 @@
+package filex
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+var procGetVolumePathNameW = modkernel32.NewProc("GetVolumePathNameW")
+
+func isMountPoint(path string) (bool, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false, err
+	}
+	if _, err := os.Stat(abs); err != nil {
+		return false, err
+	}
+	p, err := syscall.UTF16PtrFromString(abs)
+	if err != nil {
+		return false, err
+	}
+	buf := make([]uint16, syscall.MAX_PATH+1)
+	r1, _, e1 := procGetVolumePathNameW.Call(
+		uintptr(unsafe.Pointer(p)),
+		uintptr(unsafe.Pointer(&buf[0])),
+		uintptr(len(buf)),
+	)
+	if r1 == 0 {
+		return false, e1
+	}
+	volume := strings.TrimSuffix(syscall.UTF16ToString(buf), `\`)
+	return strings.EqualFold(volume, strings.TrimSuffix(abs, `\`)), nil
+}
+
+// fileDevice Windows 下不获取卷信息, 所有路径视为同一设备
+func fileDevice(path string) (uint64, error) {
+	return 0, nil
+}
@@ -0,0 +1,126 @@
+package filex
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// moveByCopy 通过复制后删除源文件的方式移动文件/目录
+func moveByCopy(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	// 复制前检查, 避免无法复制的特殊文件在删除源文件时丢失
+	if err := checkMovable(src, info); err != nil {
+		return err
+	}
+	if info.IsDir() {
+		if Exists(dst) {
+			return &os.LinkError{Op: "rename", Old: src, New: dst, Err: os.ErrExist}
+		}
+		if err := CopyDir(src, dst); err != nil {
+			os.RemoveAll(dst)
+			return err
+		}
+		return os.RemoveAll(src)
+	}
+	// 先复制到 dst 同目录下的临时文件, 成功后再重命名覆盖 dst, 失败时已存在的 dst 保持不变
+	tmp, err := os.CreateTemp(Dir(dst), "."+Basename(dst)+".tmp*")
+	if err != nil {
+		return err
+	}
+	name := tmp.Name()
+	tmp.Close()
+	if info.Mode()&os.ModeSymlink != 0 {
+		os.Remove(name)
+	}
+	err = copyEntry(src, name, info)
+	if err == nil {
+		err = os.Rename(name, dst)
+	}
+	if err != nil {
+		os.Remove(name)
+		return err
+	}
+	return os.Remove(src)
+}
+
+// ErrUnsupportedFileType 不支持的文件类型(命名管道, 套接字, 设备文件等)
+var ErrUnsupportedFileType = errors.New("unsupported file type")
+
+// checkMovable 检查 src(目录时包括其中所有条目)是否只包含普通文件, 目录及符号链接
+func checkMovable(src string, info os.FileInfo) error {
+	if !info.IsDir() {
+		return checkMovableMode(src, info.Mode())
+	}
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return checkMovableMode(p, d.Type())
+	})
+}
+
+func checkMovableMode(path string, mode os.FileMode) error {
+	if mode.IsRegular() || mode.IsDir() || mode&os.ModeSymlink != 0 {
+		return nil
+	}
+	return &os.PathError{Op: "move", Path: path, Err: ErrUnsupportedFileType}
+}
+
+// ErrDestinationExists 目标文件/目录已存在
+var ErrDestinationExists = errors.New("destination already exists")
+
+// MoveOptions 文件移动选项
+type MoveOptions struct {
+	// Overwrite 目标已存在时是否覆盖, 为 false 时返回 ErrDestinationExists
+	Overwrite bool
+	// Meta 是否同时移动元数据附属文件(见 SetMeta)
+	Meta bool
+}
+
+// MoveNoClobber 文件移动/重命名, 目标已存在时返回 ErrDestinationExists 而不是覆盖
+// 检查与移动之间并非原子操作, 不能防止其他进程在此期间创建目标
+func MoveNoClobber(src, dst string) error {
+	return MoveWithOptions(src, dst, MoveOptions{})
+}
+
+// MoveWithOptions 按指定选项移动文件/目录
+func MoveWithOptions(src, dst string, opts MoveOptions) error {
+	if !opts.Overwrite {
+		if _, err := os.Lstat(dst); err == nil {
+			return ErrDestinationExists
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := Move(src, dst); err != nil {
+		return err
+	}
+	if opts.Meta {
+		return moveMeta(src, dst)
+	}
+	return nil
+}
+
+// ErrCrossDevice 硬链接的源与目标位于不同文件系统(设备)
+var ErrCrossDevice = errors.New("cannot hard link across devices")
+
+// link 硬链接实现, 测试时可替换
+var link = os.Link
+
+// Link 为 oldpath 创建硬链接 newpath, 自动创建 newpath 所在目录
+// 源与目标位于不同文件系统时返回包含 ErrCrossDevice 的 *os.LinkError, 可使用 SameFile 确认两者为同一文件
+func Link(oldpath, newpath string) error {
+	if err := mkdirParent(newpath); err != nil {
+		return err
+	}
+	err := link(oldpath, newpath)
+	if err != nil && isCrossDevice(err) {
+		return &os.LinkError{Op: "link", Old: oldpath, New: newpath, Err: ErrCrossDevice}
+	}
+	return err
+}
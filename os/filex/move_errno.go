@@ -0,0 +1,24 @@
+//go:build !plan9
+
+package filex
+
+import (
+	"errors"
+	"runtime"
+	"syscall"
+)
+
+// errorNotSameDevice Windows ERROR_NOT_SAME_DEVICE
+const errorNotSameDevice syscall.Errno = 17
+
+// isCrossDevice 判断是否为跨设备重命名错误
+func isCrossDevice(err error) bool {
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return errno == errorNotSameDevice
+	}
+	return errno == syscall.EXDEV
+}
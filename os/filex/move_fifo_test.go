@@ -0,0 +1,38 @@
+//go:build unix && !aix && !solaris
+
+package filex
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoveCrossDeviceFIFO(t *testing.T) {
+	assert := assert.New(t)
+	crossDeviceRename(t)
+	dir := t.TempDir()
+
+	fifo := filepath.Join(dir, "pipe")
+	assert.Nil(syscall.Mkfifo(fifo, 0644))
+	err := Move(fifo, filepath.Join(dir, "moved"))
+	assert.True(errors.Is(err, ErrUnsupportedFileType))
+	assert.True(Exists(fifo), "source is kept")
+	assert.False(Exists(filepath.Join(dir, "moved")))
+
+	src := filepath.Join(dir, "src")
+	assert.Nil(PutContents(filepath.Join(src, "a.txt"), "a"))
+	assert.Nil(Mkdir(filepath.Join(src, "sub")))
+	assert.Nil(syscall.Mkfifo(filepath.Join(src, "sub", "pipe"), 0644))
+	err = Move(src, filepath.Join(dir, "dst"))
+	var pe *os.PathError
+	assert.True(errors.As(err, &pe))
+	assert.True(errors.Is(err, ErrUnsupportedFileType))
+	assert.Equal(filepath.Join(src, "sub", "pipe"), pe.Path)
+	assert.True(Exists(filepath.Join(src, "a.txt")))
+	assert.False(Exists(filepath.Join(dir, "dst")))
+}
@@ -0,0 +1,6 @@
+package filex
+
+// isCrossDevice plan9 没有 EXDEV, 重命名失败时不回退为复制
+func isCrossDevice(err error) bool {
+	return false
+}
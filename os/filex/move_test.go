@@ -0,0 +1,145 @@
+//go:build !plan9
+
+package filex
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// crossDeviceRename 模拟跨设备重命名失败
+func crossDeviceRename(t *testing.T) {
+	errno := syscall.EXDEV
+	if runtime.GOOS == "windows" {
+		errno = errorNotSameDevice
+	}
+	rename = func(oldpath, newpath string) error {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: errno}
+	}
+	t.Cleanup(func() { rename = os.Rename })
+}
+
+func TestMoveCrossDeviceFile(t *testing.T) {
+	assert := assert.New(t)
+	crossDeviceRename(t)
+	dir := t.TempDir()
+
+	src := filepath.Join(dir, "src.txt")
+	assert.Nil(PutContents(src, "content"))
+	assert.Nil(os.Chmod(src, 0600))
+	mtime := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.Nil(os.Chtimes(src, mtime, mtime))
+
+	dst := filepath.Join(dir, "other", "dst.txt")
+	assert.Nil(Move(src, dst))
+	assert.False(Exists(src))
+	assert.Equal("content", GetContents(dst))
+	info, err := os.Stat(dst)
+	assert.Nil(err)
+	assert.True(mtime.Equal(info.ModTime()))
+	if runtime.GOOS != "windows" {
+		assert.Equal(os.FileMode(0600), info.Mode().Perm())
+	}
+}
+
+func TestMoveCrossDeviceFailureKeepsDestination(t *testing.T) {
+	assert := assert.New(t)
+	crossDeviceRename(t)
+	dir := t.TempDir()
+
+	src := filepath.Join(dir, "src.txt")
+	assert.Nil(PutContents(src, "new"))
+	dst := filepath.Join(dir, "dst")
+	assert.Nil(Mkdir(dst))
+
+	assert.NotNil(Move(src, dst))
+	assert.True(IsDir(dst), "pre-existing destination is untouched")
+	assert.Equal("new", GetContents(src))
+	entries, err := os.ReadDir(dir)
+	assert.Nil(err)
+	assert.Len(entries, 2, "temp file is cleaned up")
+
+	dst = filepath.Join(dir, "dst.txt")
+	assert.Nil(PutContents(dst, "old"))
+	assert.Nil(Move(src, dst), "an existing file is replaced as by rename")
+	assert.Equal("new", GetContents(dst))
+	assert.False(Exists(src))
+}
+
+func TestMoveCrossDeviceDir(t *testing.T) {
+	assert := assert.New(t)
+	crossDeviceRename(t)
+	dir := t.TempDir()
+
+	src := filepath.Join(dir, "src")
+	assert.Nil(PutContents(filepath.Join(src, "a.txt"), "a"))
+	assert.Nil(PutContents(filepath.Join(src, "sub", "b.txt"), "b"))
+	assert.Nil(Mkdir(filepath.Join(src, "empty")))
+
+	dst := filepath.Join(dir, "dst")
+	assert.Nil(Move(src, dst))
+	assert.False(Exists(src))
+	assert.Equal("a", GetContents(filepath.Join(dst, "a.txt")))
+	assert.Equal("b", GetContents(filepath.Join(dst, "sub", "b.txt")))
+	assert.True(IsDir(filepath.Join(dst, "empty")))
+
+	assert.Nil(Mkdir(src))
+	assert.NotNil(Move(src, dst), "directory fallback must not merge into an existing destination")
+}
+
+func TestMoveNoClobber(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.txt")
+	dst := filepath.Join(dir, "dst.txt")
+	assert.Nil(PutContents(src, "new"))
+	assert.Nil(PutContents(dst, "old"))
+
+	assert.Equal(ErrDestinationExists, MoveNoClobber(src, dst))
+	assert.Equal("old", GetContents(dst))
+	assert.True(Exists(src))
+
+	assert.Nil(MoveWithOptions(src, dst, MoveOptions{Overwrite: true}))
+	assert.Equal("new", GetContents(dst))
+	assert.False(Exists(src))
+
+	fresh := filepath.Join(dir, "sub", "fresh.txt")
+	assert.Nil(MoveNoClobber(dst, fresh))
+	assert.Equal("new", GetContents(fresh))
+	assert.False(Exists(dst))
+}
+
+func TestLink(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.txt")
+	assert.Nil(PutContents(src, "v1"))
+
+	dst := filepath.Join(dir, "links", "dst.txt")
+	assert.Nil(Link(src, dst))
+	same, err := SameFile(src, dst)
+	assert.Nil(err)
+	assert.True(same)
+	assert.Nil(AppendContents(dst, "+v2"))
+	assert.Equal("v1+v2", GetContents(src))
+
+	assert.NotNil(Link(src, dst), "existing destination")
+
+	errno := syscall.EXDEV
+	if runtime.GOOS == "windows" {
+		errno = errorNotSameDevice
+	}
+	link = func(oldpath, newpath string) error {
+		return &os.LinkError{Op: "link", Old: oldpath, New: newpath, Err: errno}
+	}
+	defer func() { link = os.Link }()
+	err = Link(src, filepath.Join(dir, "other.txt"))
+	assert.True(errors.Is(err, ErrCrossDevice))
+}
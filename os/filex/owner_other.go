@@ -0,0 +1,9 @@
+//go:build !unix && !windows
+
+package filex
+
+import "errors"
+
+func copyOwnership(src, dst string) error {
+	return errors.New("copy ownership is not supported on this platform")
+}
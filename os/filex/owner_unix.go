@@ -0,0 +1,21 @@
+//go:build unix
+
+package filex
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+func copyOwnership(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return errors.New("copy ownership: unsupported file info")
+	}
+	return os.Chown(dst, int(st.Uid), int(st.Gid))
+}
@@ -0,0 +1,7 @@
+package filex
+
+import "errors"
+
+func copyOwnership(src, dst string) error {
+	return errors.New("copy ownership is not supported on windows")
+}
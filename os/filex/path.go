@@ -0,0 +1,183 @@
+package filex
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ErrPathOutsideBase 路径超出了基准目录范围
+var ErrPathOutsideBase = errors.New("path escapes base directory")
+
+// ResolveOutput 将用户提供的输出路径安全地拼接到 base 目录下
+// 拒绝绝对路径及通过 .. 跳出 base 的路径, 并创建输出文件所在目录, 返回可直接写入的绝对路径
+func ResolveOutput(base, userPath string) (string, error) {
+	p, err := joinWithin(base, userPath)
+	if err != nil {
+		return "", err
+	}
+	if abs, _ := filepath.Abs(base); p == abs {
+		return "", ErrPathOutsideBase
+	}
+	if err := mkdirParent(p); err != nil {
+		return "", err
+	}
+	return p, nil
+}
+
+// SafeJoin 将用户提供的相对路径安全地拼接到 root 目录下, 返回清理后的绝对路径
+// userPath 为绝对路径或通过 .. 跳出 root 时返回 ErrPathOutsideBase, 清理后仍位于 root 内的路径(如 a/../b)是允许的
+func SafeJoin(root, userPath string) (string, error) {
+	return joinWithin(root, userPath)
+}
+
+// joinWithin 拼接 root 与相对路径 rel, 返回清理后的绝对路径
+// rel 为绝对路径或结果超出 root 时返回 ErrPathOutsideBase
+func joinWithin(root, rel string) (string, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+	if filepath.IsAbs(rel) || filepath.VolumeName(rel) != "" || strings.HasPrefix(rel, `\`) || strings.HasPrefix(rel, "/") {
+		return "", ErrPathOutsideBase
+	}
+	p := filepath.Join(absRoot, rel)
+	if !isWithin(absRoot, p) {
+		return "", ErrPathOutsideBase
+	}
+	return p, nil
+}
+
+// isWithin 判断已清理的绝对路径 p 是否位于 root 之内(含 root 本身)
+func isWithin(root, p string) bool {
+	r, err := filepath.Rel(root, p)
+	if err != nil {
+		return false
+	}
+	return r != ".." && !strings.HasPrefix(r, ".."+string(filepath.Separator))
+}
+
+// SplitName 将路径拆分为目录, 不含扩展名的文件名, 扩展名(含.)
+// 以 . 开头且不含其他 . 的文件(如 .gitignore)视为没有扩展名
+func SplitName(path string) (dir, name, ext string) {
+	dir = Dir(path)
+	name, ext = splitExt(Basename(path))
+	return
+}
+
+// splitExt 将文件名拆分为主文件名与扩展名(含.)
+func splitExt(base string) (name, ext string) {
+	ext = filepath.Ext(base)
+	if ext == base {
+		return base, ""
+	}
+	return strings.TrimSuffix(base, ext), ext
+}
+
+// ExpandPath 展开路径开头的 ~ 为用户主目录, 展开 $VAR 及 ${VAR} 环境变量, 然后清理路径
+// Unix 下支持 ~user 形式展开其他用户的主目录; 未定义的环境变量展开为空字符串
+func ExpandPath(path string) (string, error) {
+	if strings.HasPrefix(path, "~") {
+		name, rest := path[1:], ""
+		if i := strings.IndexAny(name, `/\`); i >= 0 {
+			name, rest = name[:i], name[i:]
+		}
+		var home string
+		if name == "" {
+			h, err := Home()
+			if err != nil {
+				return "", err
+			}
+			home = h
+		} else {
+			if runtime.GOOS == "windows" {
+				return "", errors.New("expand path: ~user is not supported on windows")
+			}
+			u, err := user.Lookup(name)
+			if err != nil {
+				return "", err
+			}
+			home = u.HomeDir
+		}
+		path = home + rest
+	}
+	return filepath.Clean(os.ExpandEnv(path)), nil
+}
+
+// UniqueName 返回不与已有文件冲突的路径, path 不存在时原样返回
+// 否则在扩展名前依次追加 " (1)", " (2)", ... 直到找到不存在的路径, 如 a.txt -> a (1).txt
+// 已带序号的文件名不会被解析, 如 a (1).txt 冲突时得到 a (1) (1).txt
+func UniqueName(path string) string {
+	return UniqueNameSep(path, " (%d)")
+}
+
+// UniqueNameSep 同 UniqueName, format 为包含一个 %d 的序号后缀格式, 如 "_%d" 得到 a_1.txt
+func UniqueNameSep(path string, format string) string {
+	if !Exists(path) && !IsSymlink(path) {
+		return path
+	}
+	dir, name, ext := SplitName(path)
+	for i := 1; ; i++ {
+		p := filepath.Join(dir, name+fmt.Sprintf(format, i)+ext)
+		if !Exists(p) && !IsSymlink(p) {
+			return p
+		}
+	}
+}
+
+// CommonDir 返回所有路径的最深公共上级目录(绝对路径), 路径先转换为绝对路径并清理
+// 已存在的目录自身可作为结果, 其他路径(文件或不存在的路径)取其所在目录; 只有一个路径时返回其所在目录(目录则返回自身)
+// 路径位于不同卷(如 Windows 下的不同盘符)时返回错误
+func CommonDir(paths ...string) (string, error) {
+	if len(paths) == 0 {
+		return "", errors.New("common dir: no paths")
+	}
+	abs := make([]string, len(paths))
+	for i, p := range paths {
+		a, err := filepath.Abs(p)
+		if err != nil {
+			return "", err
+		}
+		abs[i] = a
+	}
+	common := abs[0]
+	if !IsDir(common) {
+		common = filepath.Dir(common)
+	}
+	for _, p := range abs[1:] {
+		for !isWithin(common, p) {
+			parent := filepath.Dir(common)
+			if parent == common {
+				return "", errors.New("common dir: paths have no common ancestor")
+			}
+			common = parent
+		}
+		if p == common && !IsDir(p) {
+			common = filepath.Dir(common)
+		}
+	}
+	return common, nil
+}
+
+// AbsPath 将路径转换为清理后的绝对路径, 与 RealPath 不同, 不要求路径存在
+func AbsPath(path string) (string, error) {
+	return filepath.Abs(path)
+}
+
+// RelPath 返回 target 相对于 base 的路径, 两者先通过 AbsPath 转换为绝对路径
+// target 位于 base 之外时结果以 ../ 开头, 两者相同时返回 "."
+func RelPath(base, target string) (string, error) {
+	b, err := AbsPath(base)
+	if err != nil {
+		return "", err
+	}
+	t, err := AbsPath(target)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Rel(b, t)
+}
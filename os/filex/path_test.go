@@ -0,0 +1,234 @@
+package filex
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveOutput(t *testing.T) {
+	assert := assert.New(t)
+	base := t.TempDir()
+
+	p, err := ResolveOutput(base, "reports/2018/out.csv")
+	assert.Nil(err)
+	assert.Equal(filepath.Join(base, "reports", "2018", "out.csv"), p)
+	assert.True(IsDir(filepath.Join(base, "reports", "2018")))
+
+	p, err = ResolveOutput(base, "a/../b/./c.txt")
+	assert.Nil(err)
+	assert.Equal(filepath.Join(base, "b", "c.txt"), p)
+
+	for _, bad := range []string{"../evil.txt", "a/../../evil.txt", "/etc/passwd", "", ".", "a/.."} {
+		_, err = ResolveOutput(base, bad)
+		assert.Equal(ErrPathOutsideBase, err, bad)
+	}
+	_, err = os.Stat(filepath.Join(filepath.Dir(base), "evil.txt"))
+	assert.True(os.IsNotExist(err))
+}
+
+func TestSplitName(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		path, dir, name, ext string
+	}{
+		{"/a/b/c.tar.gz", "/a/b", "c.tar", ".gz"},
+		{"/a/b/.gitignore", "/a/b", ".gitignore", ""},
+		{".gitignore", ".", ".gitignore", ""},
+		{"README", ".", "README", ""},
+		{"docs/report.pdf", "docs", "report", ".pdf"},
+		{"a/.env.local", "a", ".env", ".local"},
+	}
+	for _, tt := range tests {
+		dir, name, ext := SplitName(filepath.FromSlash(tt.path))
+		assert.Equal(filepath.FromSlash(tt.dir), dir, tt.path)
+		assert.Equal(tt.name, name, tt.path)
+		assert.Equal(tt.ext, ext, tt.path)
+	}
+}
+
+func TestExtName(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("jpg", ExtName("/photos/IMG_001.JPG"))
+	assert.Equal("gz", ExtName("archive.tar.gz"))
+	assert.Equal("", ExtName("README"))
+	assert.Equal("", ExtName(".gitignore"))
+	assert.Equal("local", ExtName(".env.local"))
+
+	assert.Equal([]string{".tar", ".gz"}, Exts("/a/archive.tar.gz"))
+	assert.Equal([]string{".JPG"}, Exts("IMG.JPG"))
+	assert.Nil(Exts("README"))
+	assert.Nil(Exts(".gitignore"))
+	assert.Equal([]string{".local"}, Exts(".env.local"))
+}
+
+func TestBasenameNoExt(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("report", BasenameNoExt(filepath.FromSlash("/a/b/report.pdf")))
+	assert.Equal(".env", BasenameNoExt(".env"))
+	assert.Equal("a.b", BasenameNoExt("a.b.c"))
+	assert.Equal("README", BasenameNoExt("README"))
+	assert.Equal("report", Stem("report.pdf"))
+}
+
+func TestSafeJoin(t *testing.T) {
+	assert := assert.New(t)
+	root := t.TempDir()
+
+	p, err := SafeJoin(root, "static/css/site.css")
+	assert.Nil(err)
+	assert.Equal(filepath.Join(root, "static", "css", "site.css"), p)
+
+	p, err = SafeJoin(root, "a/b/../../c")
+	assert.Nil(err)
+	assert.Equal(filepath.Join(root, "c"), p)
+
+	p, err = SafeJoin(root, ".")
+	assert.Nil(err)
+	assert.Equal(root, p)
+
+	for _, bad := range []string{"../../etc/passwd", "/etc/passwd", "a/../../b", ".."} {
+		_, err = SafeJoin(root, bad)
+		assert.Equal(ErrPathOutsideBase, err, bad)
+	}
+
+	// 前缀相同的兄弟目录不属于 root
+	_, err = SafeJoin(root, "../"+filepath.Base(root)+"-evil/x")
+	assert.Equal(ErrPathOutsideBase, err)
+}
+
+func TestExpandPath(t *testing.T) {
+	assert := assert.New(t)
+	home, err := Home()
+	assert.Nil(err)
+	t.Setenv("GOX_TEST_HOME", home)
+	t.Setenv("TMPDIR", "/var/tmp")
+
+	p, err := ExpandPath("~/x")
+	assert.Nil(err)
+	assert.Equal(filepath.Join(home, "x"), p)
+
+	p, err = ExpandPath("~")
+	assert.Nil(err)
+	assert.Equal(filepath.Clean(home), p)
+
+	p, err = ExpandPath("$GOX_TEST_HOME/x")
+	assert.Nil(err)
+	assert.Equal(filepath.Join(home, "x"), p)
+
+	p, err = ExpandPath("${TMPDIR}/x")
+	assert.Nil(err)
+	assert.Equal(filepath.FromSlash("/var/tmp/x"), p)
+
+	p, err = ExpandPath("a/./b/../c")
+	assert.Nil(err)
+	assert.Equal(filepath.FromSlash("a/c"), p)
+
+	p, err = ExpandPath("/opt/$GOX_UNDEFINED_VAR/bin")
+	assert.Nil(err)
+	assert.Equal(filepath.FromSlash("/opt/bin"), p)
+
+	if runtime.GOOS != "windows" {
+		if u, err := user.Current(); err == nil {
+			p, err = ExpandPath("~" + u.Username + "/y")
+			assert.Nil(err)
+			assert.Equal(filepath.Join(u.HomeDir, "y"), p)
+		}
+		_, err = ExpandPath("~gox-no-such-user/y")
+		assert.NotNil(err)
+	}
+}
+
+func TestUniqueName(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	j := func(name string) string { return filepath.Join(dir, name) }
+
+	assert.Equal(j("a.txt"), UniqueName(j("a.txt")))
+	assert.Nil(PutContents(j("a.txt"), ""))
+	assert.Equal(j("a (1).txt"), UniqueName(j("a.txt")))
+	assert.Nil(PutContents(j("a (1).txt"), ""))
+	assert.Equal(j("a (2).txt"), UniqueName(j("a.txt")))
+	assert.Equal(j("a (1) (1).txt"), UniqueName(j("a (1).txt")))
+
+	assert.Nil(PutContents(j("noext"), ""))
+	assert.Equal(j("noext (1)"), UniqueName(j("noext")))
+	assert.Nil(PutContents(j(".env"), ""))
+	assert.Equal(j(".env (1)"), UniqueName(j(".env")))
+
+	assert.Equal(j("a_1.txt"), UniqueNameSep(j("a.txt"), "_%d"))
+}
+
+func TestCommonDir(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	j := func(elem ...string) string { return filepath.Join(append([]string{dir}, elem...)...) }
+	assert.Nil(PutContents(j("proj", "src", "main.go"), ""))
+	assert.Nil(PutContents(j("proj", "src", "util", "util.go"), ""))
+	assert.Nil(PutContents(j("proj", "docs", "README"), ""))
+	assert.Nil(PutContents(j("other", "x.txt"), ""))
+
+	d, err := CommonDir(j("proj", "src", "main.go"), j("proj", "src", "util", "util.go"))
+	assert.Nil(err)
+	assert.Equal(j("proj", "src"), d)
+
+	d, err = CommonDir(j("proj", "src"), j("proj", "src", "util", "util.go"))
+	assert.Nil(err)
+	assert.Equal(j("proj", "src"), d)
+
+	d, err = CommonDir(j("proj", "src", "main.go"), j("proj", "docs", "README"), j("other", "x.txt"))
+	assert.Nil(err)
+	assert.Equal(dir, d)
+
+	d, err = CommonDir(j("proj", "src", "main.go"))
+	assert.Nil(err)
+	assert.Equal(j("proj", "src"), d)
+
+	d, err = CommonDir(j("proj", "src", "main.go"), j("proj", "src", "main.go"))
+	assert.Nil(err)
+	assert.Equal(j("proj", "src"), d)
+
+	d, err = CommonDir(j("proj", "docs", "..", "src", "main.go"), j("proj", "docs", "README"))
+	assert.Nil(err)
+	assert.Equal(j("proj"), d)
+
+	_, err = CommonDir()
+	assert.NotNil(err)
+
+	if runtime.GOOS == "windows" {
+		_, err = CommonDir(`C:\a\b.txt`, `D:\c\d.txt`)
+		assert.NotNil(err)
+	}
+}
+
+func TestRelPath(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	root := filepath.Join(dir, "proj")
+
+	r, err := RelPath(root, filepath.Join(root, "src", "main.go"))
+	assert.Nil(err)
+	assert.Equal(filepath.Join("src", "main.go"), r)
+
+	r, err = RelPath(root, filepath.Join(dir, "other", "x.txt"))
+	assert.Nil(err)
+	assert.Equal(filepath.Join("..", "other", "x.txt"), r)
+
+	r, err = RelPath(root, root+string(filepath.Separator))
+	assert.Nil(err)
+	assert.Equal(".", r)
+
+	// 相对路径基于当前工作目录
+	wd, _ := os.Getwd()
+	r, err = RelPath(wd, "a/b")
+	assert.Nil(err)
+	assert.Equal(filepath.Join("a", "b"), r)
+
+	abs, err := AbsPath("x/../y")
+	assert.Nil(err)
+	assert.Equal(filepath.Join(wd, "y"), abs)
+}
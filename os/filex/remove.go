@@ -0,0 +1,226 @@
+package filex
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrRefusedDangerousPath 拒绝删除危险路径
+var ErrRefusedDangerousPath = errors.New("refused to remove dangerous path")
+
+// RemoveSafe 文件/目录删除, 删除前检查路径安全性, 不安全时返回 ErrRefusedDangerousPath
+// 拒绝删除文件系统根目录, 用户主目录及其上级目录; 指定了 allowedRoots 时, path 必须位于其中某个目录之内(不含该目录本身)
+func RemoveSafe(path string, allowedRoots ...string) error {
+	if err := checkRemovable(path, allowedRoots); err != nil {
+		return err
+	}
+	return Remove(path)
+}
+
+// checkRemovable 检查路径是否允许删除
+func checkRemovable(path string, allowedRoots []string) error {
+	p, err := resolvePath(path)
+	if err != nil {
+		return err
+	}
+	if p == filepath.VolumeName(p)+string(filepath.Separator) || filepath.Dir(p) == p {
+		return ErrRefusedDangerousPath
+	}
+	if home, err := Home(); err == nil && home != "" {
+		if h, err := resolvePath(home); err == nil && isWithin(p, h) {
+			return ErrRefusedDangerousPath
+		}
+	}
+	if len(allowedRoots) == 0 {
+		return nil
+	}
+	for _, root := range allowedRoots {
+		r, err := resolvePath(root)
+		if err != nil {
+			continue
+		}
+		if p != r && isWithin(r, p) {
+			return nil
+		}
+	}
+	return ErrRefusedDangerousPath
+}
+
+// resolvePath 转换为绝对路径, 并尽可能解析其中的符号链接
+func resolvePath(path string) (string, error) {
+	p, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	// 路径不存在时解析其最近的已存在的上级目录
+	for dir, rest := p, ""; ; {
+		if r, err := filepath.EvalSymlinks(dir); err == nil {
+			return filepath.Join(r, rest), nil
+		}
+		parent := Dir(dir)
+		if parent == dir {
+			return p, nil
+		}
+		rest = filepath.Join(Basename(dir), rest)
+		dir = parent
+	}
+}
+
+// RemoveEmptyDirs 自底向上删除 root 目录树中的空目录, 返回删除的目录数量
+// root 自身始终保留; 删除子目录后变为空的上级目录同样会被删除
+func RemoveEmptyDirs(root string) (int, error) {
+	var dirs []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && p != root {
+			dirs = append(dirs, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for i := len(dirs) - 1; i >= 0; i-- {
+		empty, err := IsEmpty(dirs[i])
+		if err != nil {
+			return n, err
+		}
+		if !empty {
+			continue
+		}
+		if err := os.Remove(dirs[i]); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// removeOne 删除单个文件或空目录, 测试时可替换
+var removeOne = os.Remove
+
+// RemoveAllBestEffort 尽可能删除 path 及其下所有内容, 遇到错误时继续删除其余条目
+// 返回所有无法删除的条目的错误(errors.Join 合并, 每个均为 *os.PathError); 含有未删除条目的目录不再尝试删除
+// path 不存在时返回 nil
+func RemoveAllBestEffort(path string) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return errors.Join(removeBestEffort(path, info.IsDir())...)
+}
+
+func removeBestEffort(path string, isDir bool) []error {
+	var errs []error
+	if isDir {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		for _, e := range entries {
+			errs = append(errs, removeBestEffort(filepath.Join(path, e.Name()), e.IsDir())...)
+		}
+		if len(errs) > 0 {
+			return errs
+		}
+	}
+	if err := removeOne(path); err != nil && !os.IsNotExist(err) {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// RemoveGlob 删除所有匹配 pattern 的文件/目录, 返回删除的数量, 各匹配项的错误合并返回
+// 每个匹配项删除前按 RemoveSafe 的规则检查, 另外拒绝删除文件系统根目录的直接子项(如 /* 匹配到的 /usr)
+// allowedRoots 含义同 RemoveSafe
+func RemoveGlob(pattern string, allowedRoots ...string) (int, error) {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	var errs []error
+	for _, m := range matches {
+		if err := checkGlobRemovable(m, allowedRoots); err != nil {
+			errs = append(errs, &os.PathError{Op: "remove", Path: m, Err: err})
+			continue
+		}
+		if err := Remove(m); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		n++
+	}
+	return n, errors.Join(errs...)
+}
+
+func checkGlobRemovable(path string, allowedRoots []string) error {
+	if err := checkRemovable(path, allowedRoots); err != nil {
+		return err
+	}
+	p, err := resolvePath(path)
+	if err != nil {
+		return err
+	}
+	if parent := filepath.Dir(p); filepath.Dir(parent) == parent {
+		return ErrRefusedDangerousPath
+	}
+	return nil
+}
+
+// CleanupOlderThan 删除 dir 目录树中最后修改时间早于 d 之前的文件, 返回已删除的文件路径
+// pattern 非空时仅删除文件名(不含目录)匹配该 glob 模式的文件; 目录不会被删除
+// 单个文件删除失败时继续处理其余文件, 所有错误合并返回
+func CleanupOlderThan(dir string, d time.Duration, pattern string) (removed []string, err error) {
+	if pattern != "" {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, err
+		}
+	}
+	cutoff := time.Now().Add(-d)
+	var errs []error
+	err = filepath.WalkDir(dir, func(p string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if e.IsDir() {
+			return nil
+		}
+		if pattern != "" {
+			if ok, _ := filepath.Match(pattern, e.Name()); !ok {
+				return nil
+			}
+		}
+		info, err := e.Info()
+		if err != nil {
+			if !os.IsNotExist(err) {
+				errs = append(errs, err)
+			}
+			return nil
+		}
+		if !info.ModTime().Before(cutoff) {
+			return nil
+		}
+		if err := removeOne(p); err != nil {
+			if !os.IsNotExist(err) {
+				errs = append(errs, err)
+			}
+			return nil
+		}
+		removed = append(removed, p)
+		return nil
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return removed, errors.Join(errs...)
+}
@@ -0,0 +1,197 @@
+package filex
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoveSafe(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	root := filepath.Join(dir, "workspace")
+	target := filepath.Join(root, "build", "out.txt")
+	assert.Nil(PutContents(target, "x"))
+	outside := filepath.Join(dir, "other.txt")
+	assert.Nil(PutContents(outside, "y"))
+
+	assert.Equal(ErrRefusedDangerousPath, RemoveSafe("/"))
+	assert.Equal(ErrRefusedDangerousPath, RemoveSafe("/", root))
+	home, err := Home()
+	assert.Nil(err)
+	assert.Equal(ErrRefusedDangerousPath, RemoveSafe(home))
+	assert.Equal(ErrRefusedDangerousPath, RemoveSafe(filepath.Dir(home)))
+
+	assert.Equal(ErrRefusedDangerousPath, RemoveSafe(outside, root))
+	assert.True(Exists(outside))
+	assert.Equal(ErrRefusedDangerousPath, RemoveSafe(root, root), "the allowed root itself is kept")
+	assert.Equal(ErrRefusedDangerousPath, RemoveSafe(filepath.Join(root, "..", "other.txt"), root))
+
+	assert.Nil(RemoveSafe(filepath.Join(root, "build"), root))
+	assert.False(Exists(target))
+	assert.True(IsDir(root))
+}
+
+func TestRemoveEmptyDirs(t *testing.T) {
+	assert := assert.New(t)
+	root := t.TempDir()
+	assert.Nil(Mkdir(filepath.Join(root, "a", "b", "c")))
+	assert.Nil(Mkdir(filepath.Join(root, "d")))
+	assert.Nil(PutContents(filepath.Join(root, "e", "keep.txt"), "x"))
+	assert.Nil(Mkdir(filepath.Join(root, "e", "empty")))
+
+	n, err := RemoveEmptyDirs(root)
+	assert.Nil(err)
+	assert.Equal(5, n)
+	assert.False(Exists(filepath.Join(root, "a")))
+	assert.False(Exists(filepath.Join(root, "d")))
+	assert.False(Exists(filepath.Join(root, "e", "empty")))
+	assert.True(Exists(filepath.Join(root, "e", "keep.txt")))
+	assert.True(IsDir(root))
+
+	assert.Nil(Remove(filepath.Join(root, "e")))
+	n, err = RemoveEmptyDirs(root)
+	assert.Nil(err)
+	assert.Equal(0, n)
+	assert.True(IsDir(root), "root itself is kept")
+}
+
+func TestRemoveAllBestEffort(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	root := filepath.Join(dir, "tree")
+	locked := filepath.Join(root, "b", "locked.txt")
+	for _, p := range []string{filepath.Join(root, "a", "1.txt"), filepath.Join(root, "b", "2.txt"), locked, filepath.Join(root, "c.txt")} {
+		assert.Nil(PutContents(p, "x"))
+	}
+
+	old := removeOne
+	removeOne = func(name string) error {
+		if name == locked {
+			return &os.PathError{Op: "remove", Path: name, Err: syscall.EBUSY}
+		}
+		return old(name)
+	}
+	defer func() { removeOne = old }()
+
+	err := RemoveAllBestEffort(root)
+	var pe *os.PathError
+	assert.True(errors.As(err, &pe))
+	assert.Equal(locked, pe.Path)
+	assert.True(Exists(locked))
+	assert.False(Exists(filepath.Join(root, "a")))
+	assert.False(Exists(filepath.Join(root, "b", "2.txt")))
+	assert.False(Exists(filepath.Join(root, "c.txt")))
+
+	removeOne = old
+	assert.Nil(RemoveAllBestEffort(root))
+	assert.False(Exists(root))
+	assert.Nil(RemoveAllBestEffort(root))
+}
+
+func TestRemoveGlob(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	for _, name := range []string{"a.tmp", "b.tmp", "keep.txt", "c.tmp.bak"} {
+		assert.Nil(PutContents(filepath.Join(dir, name), "x"))
+	}
+	assert.Nil(PutContents(filepath.Join(dir, "d.tmp", "inner.txt"), "x"))
+
+	n, err := RemoveGlob(filepath.Join(dir, "*.tmp"))
+	assert.Nil(err)
+	assert.Equal(3, n)
+	assert.False(Exists(filepath.Join(dir, "a.tmp")))
+	assert.False(Exists(filepath.Join(dir, "d.tmp")))
+	assert.True(Exists(filepath.Join(dir, "keep.txt")))
+	assert.True(Exists(filepath.Join(dir, "c.tmp.bak")))
+
+	n, err = RemoveGlob(filepath.Join(dir, "*.none"))
+	assert.Nil(err)
+	assert.Equal(0, n)
+
+	// 不在 allowedRoots 中的匹配项被拒绝
+	n, err = RemoveGlob(filepath.Join(dir, "*.txt"), filepath.Join(dir, "other"))
+	assert.True(errors.Is(err, ErrRefusedDangerousPath))
+	assert.Equal(0, n)
+	assert.True(Exists(filepath.Join(dir, "keep.txt")))
+
+	_, err = RemoveGlob("[")
+	assert.NotNil(err)
+}
+
+func TestCheckGlobRemovable(t *testing.T) {
+	assert := assert.New(t)
+	root := string(filepath.Separator)
+	if vol := filepath.VolumeName(os.TempDir()); vol != "" {
+		root = vol + root
+	}
+	assert.Equal(ErrRefusedDangerousPath, checkGlobRemovable(filepath.Join(root, "usr"), nil))
+	assert.Equal(ErrRefusedDangerousPath, checkGlobRemovable(root, nil))
+	assert.Nil(checkGlobRemovable(filepath.Join(t.TempDir(), "x.tmp"), nil))
+}
+
+func TestCleanupOlderThan(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	past := time.Now().Add(-48 * time.Hour)
+	oldLog := filepath.Join(dir, "a.log")
+	oldNested := filepath.Join(dir, "sub", "b.log")
+	oldTxt := filepath.Join(dir, "c.txt")
+	newLog := filepath.Join(dir, "d.log")
+	for _, p := range []string{oldLog, oldNested, oldTxt, newLog} {
+		assert.Nil(PutContents(p, "x"))
+	}
+	for _, p := range []string{oldLog, oldNested, oldTxt, filepath.Join(dir, "sub")} {
+		assert.Nil(os.Chtimes(p, past, past))
+	}
+
+	removed, err := CleanupOlderThan(dir, 24*time.Hour, "*.log")
+	assert.Nil(err)
+	assert.ElementsMatch([]string{oldLog, oldNested}, removed)
+	assert.False(Exists(oldLog))
+	assert.False(Exists(oldNested))
+	assert.True(Exists(oldTxt))
+	assert.True(Exists(newLog))
+	assert.True(IsDir(filepath.Join(dir, "sub")), "directories are kept")
+
+	removed, err = CleanupOlderThan(dir, 24*time.Hour, "")
+	assert.Nil(err)
+	assert.Equal([]string{oldTxt}, removed)
+	assert.True(Exists(newLog))
+
+	_, err = CleanupOlderThan(dir, time.Hour, "[")
+	assert.Equal(filepath.ErrBadPattern, err)
+}
+
+func TestCleanupOlderThanErrors(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	past := time.Now().Add(-time.Hour)
+	locked := filepath.Join(dir, "a.tmp")
+	other := filepath.Join(dir, "b.tmp")
+	for _, p := range []string{locked, other} {
+		assert.Nil(PutContents(p, "x"))
+		assert.Nil(os.Chtimes(p, past, past))
+	}
+
+	old := removeOne
+	removeOne = func(name string) error {
+		if name == locked {
+			return &os.PathError{Op: "remove", Path: name, Err: syscall.EBUSY}
+		}
+		return old(name)
+	}
+	defer func() { removeOne = old }()
+
+	removed, err := CleanupOlderThan(dir, time.Minute, "*.tmp")
+	assert.Equal([]string{other}, removed)
+	var pe *os.PathError
+	assert.True(errors.As(err, &pe))
+	assert.Equal(locked, pe.Path)
+	assert.True(Exists(locked))
+}
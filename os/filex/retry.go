@@ -0,0 +1,70 @@
+package filex
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"time"
+)
+
+// copyOnce, moveOnce 单次复制/移动实现, 测试时可替换
+var (
+	copyOnce = Copy
+	moveOnce = Move
+)
+
+// CopyRetry 文件复制, 遇到临时性 I/O 错误(EIO, EAGAIN, EBUSY, 超时等)时按指数退避重试, 最多尝试 attempts 次
+// 每次失败后删除未完成的目标文件; 其他错误(文件不存在, 权限不足, 磁盘已满等)不重试直接返回
+func CopyRetry(src, dst string, attempts int, backoff time.Duration) error {
+	return retry(attempts, backoff, func() error {
+		err := copyOnce(src, dst)
+		if err != nil && isTransient(err) {
+			os.Remove(dst)
+		}
+		return err
+	})
+}
+
+// MoveRetry 文件移动, 遇到临时性 I/O 错误时按指数退避重试, 最多尝试 attempts 次
+func MoveRetry(src, dst string, attempts int, backoff time.Duration) error {
+	return retry(attempts, backoff, func() error {
+		return moveOnce(src, dst)
+	})
+}
+
+// retry 执行 fn, 返回临时性错误时等待 backoff 后重试, 每次等待时间加倍
+func retry(attempts int, backoff time.Duration, fn func() error) error {
+	if attempts < 1 {
+		attempts = 1
+	}
+	var err error
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		if err = fn(); err == nil || !isTransient(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// transientErrors 临时性错误, 重试可能成功; 其他错误(如文件不存在, 权限不足, 磁盘已满)均不重试
+var transientErrors = append([]error{
+	syscall.EIO,
+	syscall.EINTR,
+	syscall.ETIMEDOUT,
+	syscall.EBUSY,
+	os.ErrDeadlineExceeded,
+}, errnoTransient...)
+
+// isTransient 判断错误是否为临时性错误(重试可能成功)
+func isTransient(err error) bool {
+	for _, target := range transientErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,8 @@
+//go:build !plan9
+
+package filex
+
+import "syscall"
+
+// errnoTransient 部分平台才有定义的临时性错误
+var errnoTransient = []error{syscall.EAGAIN, syscall.ESTALE, syscall.ECONNRESET}
@@ -0,0 +1,4 @@
+package filex
+
+// errnoTransient plan9 没有定义 EAGAIN, ESTALE, ECONNRESET
+var errnoTransient []error
@@ -0,0 +1,87 @@
+//go:build !plan9
+
+package filex
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCopyRetry(t *testing.T) {
+	assert := assert.New(t)
+	defer func() { copyOnce = Copy }()
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.txt")
+	dst := filepath.Join(dir, "dst.txt")
+	assert.Nil(PutContents(src, "data"))
+
+	flaky := func(failures int) *int {
+		calls := 0
+		copyOnce = func(src, dst string) error {
+			calls++
+			if calls <= failures {
+				PutContents(dst, "partial")
+				return &os.PathError{Op: "write", Path: dst, Err: syscall.EIO}
+			}
+			return Copy(src, dst)
+		}
+		return &calls
+	}
+
+	calls := flaky(2)
+	start := time.Now()
+	assert.Nil(CopyRetry(src, dst, 3, 10*time.Millisecond))
+	assert.Equal(3, *calls)
+	assert.True(time.Since(start) >= 30*time.Millisecond, "backoff should grow exponentially")
+	assert.Equal("data", GetContents(dst))
+
+	calls = flaky(5)
+	err := CopyRetry(src, dst, 3, time.Millisecond)
+	assert.NotNil(err)
+	assert.Equal(3, *calls)
+	assert.False(Exists(dst), "partial destination is cleaned up")
+
+	calls = flaky(0)
+	err = CopyRetry(filepath.Join(dir, "missing"), dst, 5, time.Millisecond)
+	assert.True(os.IsNotExist(err))
+	assert.Equal(1, *calls, "non-transient errors are not retried")
+
+	for _, err := range []error{
+		&os.PathError{Op: "write", Path: dst, Err: syscall.ENOSPC},
+		&os.PathError{Op: "open", Path: dst, Err: syscall.EROFS},
+		ErrChecksumMismatch,
+	} {
+		calls := 0
+		copyOnce = func(src, dst string) error {
+			calls++
+			return err
+		}
+		assert.Equal(err, CopyRetry(src, dst, 5, time.Millisecond))
+		assert.Equal(1, calls, "%v is not retried", err)
+	}
+}
+
+func TestMoveRetry(t *testing.T) {
+	assert := assert.New(t)
+	defer func() { moveOnce = Move }()
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.txt")
+	assert.Nil(PutContents(src, "data"))
+
+	calls := 0
+	moveOnce = func(src, dst string) error {
+		calls++
+		if calls == 1 {
+			return &os.LinkError{Op: "rename", Old: src, New: dst, Err: syscall.EAGAIN}
+		}
+		return Move(src, dst)
+	}
+	assert.Nil(MoveRetry(src, filepath.Join(dir, "dst.txt"), 2, time.Millisecond))
+	assert.Equal(2, calls)
+	assert.False(Exists(src))
+}
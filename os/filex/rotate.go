@@ -0,0 +1,128 @@
+package filex
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// RotatingWriter 按大小滚动的文件 Writer, 并发安全
+// 写入后文件大小将超过 maxBytes 时, 先将当前文件依次滚动为 path.1, path.2, ... (path.1 最新), 最多保留 maxBackups 个旧文件
+// 单次 Write 的数据总是完整写入同一个文件, 不会被拆分到两个文件中; 单次写入超过 maxBytes 时单独写入一个新文件
+type RotatingWriter struct {
+	mu         sync.Mutex
+	path       string
+	maxBytes   int64
+	maxBackups int
+	f          *os.File // 滚动失败后重新打开也失败时为 nil, 下次 Write 时重试打开
+	size       int64
+	closed     bool
+}
+
+// NewRotatingWriter 以追加模式打开(创建) path, 返回 RotatingWriter, 自动创建所在目录
+func NewRotatingWriter(path string, maxBytes int64, maxBackups int) (*RotatingWriter, error) {
+	if maxBytes <= 0 {
+		return nil, errors.New("rotating writer: max bytes must be positive")
+	}
+	if maxBackups < 0 {
+		return nil, errors.New("rotating writer: negative max backups")
+	}
+	w := &RotatingWriter{path: path, maxBytes: maxBytes, maxBackups: maxBackups}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write 写入数据, 必要时先滚动文件
+func (w *RotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	if w.f == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+	if w.size > 0 && w.size+int64(len(p)) > w.maxBytes {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.f.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close 关闭当前文件, 可重复调用
+func (w *RotatingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if w.f == nil {
+		return nil
+	}
+	err := w.f.Close()
+	w.f = nil
+	return err
+}
+
+func (w *RotatingWriter) open() error {
+	f, err := Open(w.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.f, w.size = f, info.Size()
+	return nil
+}
+
+// rotate 关闭当前文件, 将 path.(i) 重命名为 path.(i+1), path 重命名为 path.1, 然后重新打开 path
+// 删除或重命名失败时仍以追加模式重新打开 path 并返回错误, 下次 Write 时再次尝试滚动
+func (w *RotatingWriter) rotate() error {
+	err := w.f.Close()
+	w.f = nil
+	if err == nil {
+		err = w.shift()
+	}
+	if oerr := w.open(); err == nil {
+		err = oerr
+	}
+	return err
+}
+
+// shift 依次重命名备份文件及当前文件
+func (w *RotatingWriter) shift() error {
+	if w.maxBackups == 0 {
+		if err := os.Remove(w.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := os.Remove(w.backup(w.maxBackups)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := w.maxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(w.backup(i), w.backup(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(w.path, w.backup(1)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (w *RotatingWriter) backup(i int) string {
+	return w.path + "." + strconv.Itoa(i)
+}
@@ -0,0 +1,82 @@
+package filex
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotatingWriter(t *testing.T) {
+	assert := assert.New(t)
+	p := filepath.Join(t.TempDir(), "logs", "app.log")
+	w, err := NewRotatingWriter(p, 25, 2)
+	assert.Nil(err)
+
+	// 每条 9 字节, 每个文件最多容纳 2 条
+	for i := 0; i < 9; i++ {
+		n, err := fmt.Fprintf(w, "line %03d\n", i)
+		assert.Nil(err)
+		assert.Equal(9, n)
+	}
+	assert.Nil(w.Close())
+	_, err = w.Write([]byte("x"))
+	assert.Equal(os.ErrClosed, err)
+
+	assert.Equal("line 008\n", GetContents(p))
+	assert.Equal("line 006\nline 007\n", GetContents(p+".1"))
+	assert.Equal("line 004\nline 005\n", GetContents(p+".2"))
+	assert.False(Exists(p + ".3"))
+
+	// 重新打开时沿用已有大小, 超过限制的单次写入完整写入新文件
+	w, err = NewRotatingWriter(p, 25, 2)
+	assert.Nil(err)
+	big := []byte("0123456789012345678901234567890123456789\n")
+	_, err = w.Write(big)
+	assert.Nil(err)
+	assert.Nil(w.Close())
+	assert.Equal(string(big), GetContents(p))
+	assert.Equal("line 008\n", GetContents(p+".1"))
+	assert.Equal("line 006\nline 007\n", GetContents(p+".2"))
+
+	_, err = NewRotatingWriter(p, 0, 1)
+	assert.NotNil(err)
+}
+
+func TestRotatingWriterNoBackups(t *testing.T) {
+	assert := assert.New(t)
+	p := filepath.Join(t.TempDir(), "app.log")
+	w, err := NewRotatingWriter(p, 10, 0)
+	assert.Nil(err)
+	defer w.Close()
+	w.Write([]byte("aaaaaa"))
+	w.Write([]byte("bbbbbb"))
+	assert.Equal("bbbbbb", GetContents(p))
+	assert.False(Exists(p + ".1"))
+}
+
+func TestRotatingWriterRecoversFromRotateError(t *testing.T) {
+	assert := assert.New(t)
+	p := filepath.Join(t.TempDir(), "app.log")
+	w, err := NewRotatingWriter(p, 10, 2)
+	assert.Nil(err)
+	defer w.Close()
+
+	_, err = w.Write([]byte("aaaaaa"))
+	assert.Nil(err)
+	// 非空目录占用 path.2, 滚动时无法删除
+	assert.Nil(PutContents(filepath.Join(p+".2", "x"), "x"))
+	_, err = w.Write([]byte("bbbbbb"))
+	assert.NotNil(err)
+	assert.NotEqual(os.ErrClosed, err)
+	assert.Equal("aaaaaa", GetContents(p))
+
+	assert.Nil(os.RemoveAll(p + ".2"))
+	n, err := w.Write([]byte("cccccc"))
+	assert.Nil(err, "writer recovers once the cause is fixed")
+	assert.Equal(6, n)
+	assert.Equal("cccccc", GetContents(p))
+	assert.Equal("aaaaaa", GetContents(p+".1"))
+}
@@ -0,0 +1,29 @@
+package filex
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NextSequence 将文件中保存的整数加 1 后原子写回, 返回新值, 可用作跨进程计数器
+// 读写期间持有 path 的互斥锁(见 Lock); 文件不存在或为空时从 1 开始
+func NextSequence(path string) (int64, error) {
+	var next int64
+	err := Edit(path, func(data []byte) ([]byte, error) {
+		var cur int64
+		if s := strings.TrimSpace(string(data)); s != "" {
+			var err error
+			cur, err = strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("sequence %s: %w", path, err)
+			}
+		}
+		next = cur + 1
+		return []byte(strconv.FormatInt(next, 10)), nil
+	}, EditOptions{Lock: true})
+	if err != nil {
+		return 0, err
+	}
+	return next, nil
+}
@@ -0,0 +1,44 @@
+package filex
+
+import (
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextSequence(t *testing.T) {
+	assert := assert.New(t)
+	path := filepath.Join(t.TempDir(), "seq")
+
+	const workers, each = 8, 25
+	var mu sync.Mutex
+	var got []int64
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < each; j++ {
+				n, err := NextSequence(path)
+				assert.Nil(err)
+				mu.Lock()
+				got = append(got, n)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+	for i, n := range got {
+		assert.Equal(int64(i+1), n)
+	}
+	assert.Equal("200", GetContents(path))
+
+	assert.Nil(PutContents(path, "not a number"))
+	_, err := NextSequence(path)
+	assert.NotNil(err)
+}
@@ -0,0 +1,69 @@
+package filex
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strconv"
+)
+
+// SplitFile 将文件按 chunkSize 字节切分为 path.part0, path.part1, ... 分片文件, 返回分片路径
+// 最后一个分片可能小于 chunkSize, 空文件生成一个空分片; 失败时删除已生成的分片
+func SplitFile(path string, chunkSize int64) ([]string, error) {
+	if chunkSize <= 0 {
+		return nil, errors.New("split: chunk size must be positive")
+	}
+	in, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+	fi, err := in.Stat()
+	if err != nil {
+		return nil, err
+	}
+	var parts []string
+	for i, remain := 0, fi.Size(); i == 0 || remain > 0; i++ {
+		n := chunkSize
+		if remain < n {
+			n = remain
+		}
+		part := path + ".part" + strconv.Itoa(i)
+		err := writeStream(part, fi.Mode().Perm(), func(w io.Writer) error {
+			_, err := io.CopyN(w, in, n)
+			return err
+		})
+		if err != nil {
+			for _, p := range parts {
+				os.Remove(p)
+			}
+			return nil, err
+		}
+		parts = append(parts, part)
+		remain -= n
+	}
+	return parts, nil
+}
+
+// JoinFiles 按顺序拼接分片文件写入 dst, 自动创建 dst 所在目录, 失败时删除未完成的 dst
+func JoinFiles(parts []string, dst string) error {
+	return writeStream(dst, 0666, func(w io.Writer) error {
+		buf := make([]byte, copyBufferSize)
+		for _, p := range parts {
+			if err := appendPart(w, p, buf); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func appendPart(w io.Writer, part string, buf []byte) error {
+	in, err := os.Open(part)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	_, err = io.CopyBuffer(w, in, buf)
+	return err
+}
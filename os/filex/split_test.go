@@ -0,0 +1,53 @@
+package filex
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitJoinFiles(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	src := filepath.Join(dir, "data.bin")
+	data := bytes.Repeat([]byte("0123456789"), 25)
+	assert.Nil(PutBinContents(src, data))
+
+	parts, err := SplitFile(src, 100)
+	assert.Nil(err)
+	assert.Equal([]string{src + ".part0", src + ".part1", src + ".part2"}, parts)
+	for i, want := range []int64{100, 100, 50} {
+		assert.Equal(want, Size(parts[i]))
+	}
+
+	dst := filepath.Join(dir, "out", "data.bin")
+	assert.Nil(JoinFiles(parts, dst))
+	got, err := os.ReadFile(dst)
+	assert.Nil(err)
+	assert.Equal(data, got)
+
+	// 恰好整除时不产生空分片
+	parts, err = SplitFile(src, 125)
+	assert.Nil(err)
+	assert.Len(parts, 2)
+
+	_, err = SplitFile(src, 0)
+	assert.NotNil(err)
+
+	err = JoinFiles([]string{parts[0], filepath.Join(dir, "missing")}, dst+".2")
+	assert.NotNil(err)
+	assert.False(Exists(dst + ".2"))
+}
+
+func TestSplitEmptyFile(t *testing.T) {
+	assert := assert.New(t)
+	src := filepath.Join(t.TempDir(), "empty")
+	assert.Nil(Create(src))
+	parts, err := SplitFile(src, 10)
+	assert.Nil(err)
+	assert.Len(parts, 1)
+	assert.Equal(int64(0), Size(parts[0]))
+}
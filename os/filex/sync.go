@@ -0,0 +1,158 @@
+package filex
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// SyncOptions 目录同步选项
+type SyncOptions struct {
+	// Delete 是否删除目标目录中源目录不存在的文件/目录
+	Delete bool
+	// Checksum 是否按文件内容判断变化, 默认只比较大小及修改时间
+	Checksum bool
+	// NameFunc 将文件(非目录)相对于源目录的路径映射为相对于目标目录的路径, 返回空字符串时跳过该文件, 同 CopyDirOptions.NameFunc
+	NameFunc func(relPath string) string
+}
+
+// SyncResult 目录同步结果
+type SyncResult struct {
+	// Copied 复制的文件数
+	Copied int
+	// Deleted 删除的文件/目录数
+	Deleted int
+	// Skipped 未发生变化而跳过的文件数
+	Skipped int
+}
+
+// Sync 同步目录, 使 dstDir 与 srcDir 保持一致
+// 复制新增及发生变化的文件(保留权限及修改时间), opts.Delete 为 true 时删除 dstDir 中多余的文件
+func Sync(srcDir, dstDir string, opts SyncOptions) (SyncResult, error) {
+	var result SyncResult
+	dstRoot, err := filepath.Abs(dstDir)
+	if err != nil {
+		return result, err
+	}
+	// keep 记录目标目录中应保留的相对路径
+	keep := make(map[string]bool)
+	err = filepath.WalkDir(srcDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(srcDir, p)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			keep[rel] = true
+			return EnsureDir(filepath.Join(dstRoot, rel), info.Mode().Perm()|0700)
+		}
+		target, ok, err := mapName(opts.NameFunc, dstRoot, rel)
+		if err != nil || !ok {
+			return err
+		}
+		// 映射后的路径及其上级目录均需保留
+		if rel, err = filepath.Rel(dstRoot, target); err != nil {
+			return err
+		}
+		for ; rel != "."; rel = filepath.Dir(rel) {
+			keep[rel] = true
+		}
+		changed, err := syncChanged(p, target, info, opts)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			result.Skipped++
+			return nil
+		}
+		if err := removeConflicting(target); err != nil {
+			return err
+		}
+		if err := copyEntry(p, target, info); err != nil {
+			return err
+		}
+		result.Copied++
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+	if !opts.Delete {
+		return result, nil
+	}
+	err = filepath.WalkDir(dstDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dstDir, p)
+		if err != nil {
+			return err
+		}
+		if keep[rel] {
+			return nil
+		}
+		if err := os.RemoveAll(p); err != nil {
+			return err
+		}
+		result.Deleted++
+		if d.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return result, err
+}
+
+// syncChanged 判断源文件相对目标文件是否发生变化
+func syncChanged(src, dst string, info os.FileInfo, opts SyncOptions) (bool, error) {
+	dinfo, err := os.Lstat(dst)
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if dinfo.Mode().Type() != info.Mode().Type() {
+		return true, nil
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		a, err := os.Readlink(src)
+		if err != nil {
+			return false, err
+		}
+		b, err := os.Readlink(dst)
+		if err != nil {
+			return false, err
+		}
+		return a != b, nil
+	}
+	if dinfo.Size() != info.Size() {
+		return true, nil
+	}
+	if opts.Checksum {
+		same, err := SameContent(src, dst)
+		return !same, err
+	}
+	return !dinfo.ModTime().Equal(info.ModTime()), nil
+}
+
+// removeConflicting 删除复制前已存在的目标(目录或符号链接), 普通文件由复制时覆盖
+func removeConflicting(path string) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode().IsRegular() {
+		return nil
+	}
+	return os.RemoveAll(path)
+}
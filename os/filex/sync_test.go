@@ -0,0 +1,89 @@
+package filex
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSync(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	dst := filepath.Join(dir, "dst")
+	assert.Nil(PutContents(filepath.Join(src, "a.txt"), "a"))
+	assert.Nil(PutContents(filepath.Join(src, "sub", "b.txt"), "b"))
+
+	res, err := Sync(src, dst, SyncOptions{})
+	assert.Nil(err)
+	assert.Equal(SyncResult{Copied: 2}, res)
+	assert.Equal("b", GetContents(filepath.Join(dst, "sub", "b.txt")))
+
+	res, err = Sync(src, dst, SyncOptions{})
+	assert.Nil(err)
+	assert.Equal(SyncResult{Skipped: 2}, res)
+
+	// 修改, 新增, 删除
+	assert.Nil(PutContents(filepath.Join(src, "a.txt"), "aa"))
+	assert.Nil(PutContents(filepath.Join(src, "c.txt"), "c"))
+	assert.Nil(os.Remove(filepath.Join(src, "sub", "b.txt")))
+	assert.Nil(PutContents(filepath.Join(dst, "extra", "x.txt"), "x"))
+
+	res, err = Sync(src, dst, SyncOptions{})
+	assert.Nil(err)
+	assert.Equal(SyncResult{Copied: 2}, res)
+	assert.True(Exists(filepath.Join(dst, "sub", "b.txt")), "no deletion without Delete")
+	assert.Equal("aa", GetContents(filepath.Join(dst, "a.txt")))
+
+	res, err = Sync(src, dst, SyncOptions{Delete: true})
+	assert.Nil(err)
+	assert.Equal(SyncResult{Skipped: 2, Deleted: 2}, res)
+	assert.False(Exists(filepath.Join(dst, "sub", "b.txt")))
+	assert.False(Exists(filepath.Join(dst, "extra")))
+	assert.True(IsDir(filepath.Join(dst, "sub")))
+
+	// 内容变化但大小及修改时间相同, 只有 Checksum 模式能识别
+	info, _ := os.Stat(filepath.Join(src, "a.txt"))
+	assert.Nil(PutContents(filepath.Join(dst, "a.txt"), "zz"))
+	assert.Nil(os.Chtimes(filepath.Join(dst, "a.txt"), time.Now(), info.ModTime()))
+	res, err = Sync(src, dst, SyncOptions{})
+	assert.Nil(err)
+	assert.Equal(0, res.Copied)
+	res, err = Sync(src, dst, SyncOptions{Checksum: true})
+	assert.Nil(err)
+	assert.Equal(1, res.Copied)
+	assert.Equal("aa", GetContents(filepath.Join(dst, "a.txt")))
+}
+
+func TestSyncNameFunc(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	src, dst := filepath.Join(dir, "src"), filepath.Join(dir, "dst")
+	assert.Nil(PutContents(filepath.Join(src, "conf", "app.ini.tmpl"), "a"))
+	assert.Nil(PutContents(filepath.Join(src, "notes.txt"), "n"))
+	nameFunc := func(rel string) string {
+		if strings.HasSuffix(rel, ".txt") {
+			return ""
+		}
+		return filepath.Join("out", strings.TrimSuffix(rel, ".tmpl"))
+	}
+
+	res, err := Sync(src, dst, SyncOptions{NameFunc: nameFunc, Delete: true})
+	assert.Nil(err)
+	assert.Equal(SyncResult{Copied: 1}, res)
+	assert.Equal("a", GetContents(filepath.Join(dst, "out", "conf", "app.ini")))
+	assert.False(Exists(filepath.Join(dst, "notes.txt")))
+
+	assert.True(IsDir(filepath.Join(dst, "conf")), "directories keep their source names")
+
+	// 映射后的目标文件不会被当作多余文件删除
+	assert.Nil(PutContents(filepath.Join(dst, "stale.txt"), ""))
+	res, err = Sync(src, dst, SyncOptions{NameFunc: nameFunc, Delete: true})
+	assert.Nil(err)
+	assert.Equal(SyncResult{Skipped: 1, Deleted: 1}, res)
+	assert.True(Exists(filepath.Join(dst, "out", "conf", "app.ini")))
+}
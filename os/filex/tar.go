@@ -0,0 +1,191 @@
+package filex
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Tar 将 srcDir 目录递归打包为 tar 文件 dstTar, gzipOut 为 true 时使用 gzip 压缩
+// 保留相对路径, 文件权限, 修改时间, 目录及符号链接
+func Tar(srcDir, dstTar string, gzipOut bool) error {
+	absDst, err := filepath.Abs(dstTar)
+	if err != nil {
+		return err
+	}
+	return writeStream(dstTar, 0666, func(w io.Writer) error {
+		var zw *gzip.Writer
+		if gzipOut {
+			zw = gzip.NewWriter(w)
+			w = zw
+		}
+		tw := tar.NewWriter(w)
+		err := filepath.WalkDir(srcDir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if p == srcDir {
+				return nil
+			}
+			if abs, _ := filepath.Abs(p); abs == absDst {
+				return nil
+			}
+			return tarEntry(tw, srcDir, p, d)
+		})
+		if err != nil {
+			return err
+		}
+		if err := tw.Close(); err != nil {
+			return err
+		}
+		if zw != nil {
+			return zw.Close()
+		}
+		return nil
+	})
+}
+
+func tarEntry(tw *tar.Writer, root, p string, d fs.DirEntry) error {
+	info, err := d.Info()
+	if err != nil {
+		return err
+	}
+	var link string
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		if link, err = os.Readlink(p); err != nil {
+			return err
+		}
+	case !info.Mode().IsRegular() && !info.IsDir():
+		return nil
+	}
+	header, err := tar.FileInfoHeader(info, filepath.ToSlash(link))
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(root, p)
+	if err != nil {
+		return err
+	}
+	header.Name = filepath.ToSlash(rel)
+	if info.IsDir() {
+		header.Name += "/"
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.CopyBuffer(tw, f, make([]byte, copyBufferSize))
+	return err
+}
+
+// Untar 将 tar 文件 srcTar 解包到 dstDir 目录
+// gzipIn 为 true 时按 gzip 压缩格式读取, 为 false 时根据文件头自动识别是否经过 gzip 压缩
+// 包含绝对路径, 通过 .. 或已解包的符号链接跳出 dstDir 的条目, 或指向 dstDir 之外的符号链接时返回 ErrPathOutsideBase
+func Untar(srcTar, dstDir string, gzipIn bool) error {
+	f, err := os.Open(srcTar)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	br := bufio.NewReader(f)
+	var r io.Reader = br
+	if !gzipIn {
+		magic, _ := br.Peek(2)
+		gzipIn = len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b
+	}
+	if gzipIn {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	var dirs []dirMode
+	// realDir 解析符号链接后的 dstDir, 每个条目的真实路径都须位于其中,
+	// 以防先解包的符号链接(可能层层相连)使后续条目写到 dstDir 之外
+	realDir, err := resolvePath(dstDir)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		target, err := joinWithin(dstDir, filepath.FromSlash(header.Name))
+		if err != nil {
+			return fmt.Errorf("untar %s: %w", header.Name, err)
+		}
+		real, err := resolvePath(target)
+		if err != nil {
+			return err
+		}
+		if !isWithin(realDir, real) {
+			return fmt.Errorf("untar %s: %w", header.Name, ErrPathOutsideBase)
+		}
+		mode := header.FileInfo().Mode()
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := MkdirMode(target, mode.Perm()|0700); err != nil {
+				return err
+			}
+			dirs = append(dirs, dirMode{path: target, perm: mode.Perm(), modTime: header.ModTime})
+		case tar.TypeReg:
+			// 已存在的同名符号链接先删除, 不写入其指向的文件
+			if info, err := os.Lstat(target); err == nil && info.Mode()&os.ModeSymlink != 0 {
+				if err := os.Remove(target); err != nil {
+					return err
+				}
+			}
+			err := writeStream(target, mode.Perm(), func(w io.Writer) error {
+				_, err := io.CopyBuffer(w, tr, make([]byte, copyBufferSize))
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			if err := os.Chtimes(target, header.ModTime, header.ModTime); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			link := filepath.FromSlash(header.Linkname)
+			resolved := link
+			if !filepath.IsAbs(link) {
+				resolved = filepath.Join(Dir(real), link)
+			}
+			if resolved, err = resolvePath(resolved); err != nil {
+				return err
+			}
+			if !isWithin(realDir, resolved) {
+				return fmt.Errorf("untar %s: %w", header.Name, ErrPathOutsideBase)
+			}
+			if err := mkdirParent(target); err != nil {
+				return err
+			}
+			os.Remove(target)
+			if err := os.Symlink(link, target); err != nil {
+				return err
+			}
+		}
+	}
+	return restoreDirs(dirs)
+}
@@ -0,0 +1,145 @@
+package filex
+
+import (
+	"archive/tar"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTarRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	assert.Nil(PutContents(filepath.Join(src, "a.txt"), "a"))
+	assert.Nil(PutContents(filepath.Join(src, "sub", "b.txt"), "b"))
+	assert.Nil(Mkdir(filepath.Join(src, "empty")))
+	assert.Nil(os.Chmod(filepath.Join(src, "a.txt"), 0640))
+	mtime := time.Date(2018, 6, 30, 16, 39, 45, 0, time.UTC)
+	assert.Nil(os.Chtimes(filepath.Join(src, "sub", "b.txt"), mtime, mtime))
+	symlinks := runtime.GOOS != "windows"
+	if symlinks {
+		assert.Nil(os.Symlink(filepath.Join("sub", "b.txt"), filepath.Join(src, "link")))
+	}
+
+	for _, gz := range []bool{false, true} {
+		archive := filepath.Join(dir, "src.tar")
+		assert.Nil(Tar(src, archive, gz))
+		dst := filepath.Join(dir, "dst")
+		assert.Nil(Remove(dst))
+		// 自动识别 gzip
+		assert.Nil(Untar(archive, dst, false))
+
+		assert.Equal("a", GetContents(filepath.Join(dst, "a.txt")))
+		assert.Equal("b", GetContents(filepath.Join(dst, "sub", "b.txt")))
+		assert.True(IsDir(filepath.Join(dst, "empty")))
+		info, err := os.Stat(filepath.Join(dst, "sub", "b.txt"))
+		assert.Nil(err)
+		assert.True(mtime.Equal(info.ModTime()))
+		if symlinks {
+			info, err = os.Stat(filepath.Join(dst, "a.txt"))
+			assert.Nil(err)
+			assert.Equal(os.FileMode(0640), info.Mode().Perm())
+			link, err := os.Readlink(filepath.Join(dst, "link"))
+			assert.Nil(err)
+			assert.Equal(filepath.Join("sub", "b.txt"), link)
+			assert.Equal("b", GetContents(filepath.Join(dst, "link")))
+		}
+	}
+}
+
+func TestUntarTraversal(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+
+	headers := []*tar.Header{
+		{Name: "../evil.txt", Typeflag: tar.TypeReg, Mode: 0644, Size: 4},
+		{Name: "/abs/evil.txt", Typeflag: tar.TypeReg, Mode: 0644, Size: 4},
+		{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "../../etc"},
+	}
+	for _, h := range headers {
+		archive := filepath.Join(dir, "evil.tar")
+		f, err := os.Create(archive)
+		assert.Nil(err)
+		tw := tar.NewWriter(f)
+		assert.Nil(tw.WriteHeader(h))
+		if h.Size > 0 {
+			tw.Write([]byte("evil"))
+		}
+		assert.Nil(tw.Close())
+		assert.Nil(f.Close())
+
+		err = Untar(archive, filepath.Join(dir, "dst"), false)
+		assert.True(errors.Is(err, ErrPathOutsideBase), h.Name)
+		assert.False(Exists(filepath.Join(dir, "evil.txt")))
+	}
+}
+
+func TestUntarSymlinkChain(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "a", "dst")
+
+	for _, headers := range [][]*tar.Header{
+		{
+			{Name: "d1/", Typeflag: tar.TypeDir, Mode: 0755},
+			{Name: "d1/l1", Typeflag: tar.TypeSymlink, Linkname: ".."},
+			{Name: "d1/l1/l2", Typeflag: tar.TypeSymlink, Linkname: ".."},
+			{Name: "l2/evil.txt", Typeflag: tar.TypeReg, Mode: 0644, Size: 4},
+		},
+		{
+			{Name: "l1", Typeflag: tar.TypeSymlink, Linkname: "."},
+			{Name: "l1/l2", Typeflag: tar.TypeSymlink, Linkname: ".."},
+			{Name: "l2/evil.txt", Typeflag: tar.TypeReg, Mode: 0644, Size: 4},
+		},
+	} {
+		assert.Nil(os.RemoveAll(dst))
+		archive := filepath.Join(dir, "chain.tar")
+		f, err := os.Create(archive)
+		assert.Nil(err)
+		tw := tar.NewWriter(f)
+		for _, h := range headers {
+			assert.Nil(tw.WriteHeader(h))
+			if h.Size > 0 {
+				tw.Write([]byte("evil"))
+			}
+		}
+		assert.Nil(tw.Close())
+		assert.Nil(f.Close())
+
+		err = Untar(archive, dst, false)
+		assert.True(errors.Is(err, ErrPathOutsideBase), headers[len(headers)-1].Name)
+		assert.False(Exists(filepath.Join(dir, "a", "evil.txt")))
+		assert.False(Exists(filepath.Join(dir, "evil.txt")))
+	}
+}
+
+func TestUntarReplacesSymlinkWithFile(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "dst")
+	victim := filepath.Join(dst, "victim.txt")
+	assert.Nil(PutContents(victim, "keep"))
+
+	archive := filepath.Join(dir, "swap.tar")
+	f, err := os.Create(archive)
+	assert.Nil(err)
+	tw := tar.NewWriter(f)
+	assert.Nil(tw.WriteHeader(&tar.Header{Name: "name", Typeflag: tar.TypeSymlink, Linkname: "victim.txt"}))
+	assert.Nil(tw.WriteHeader(&tar.Header{Name: "name", Typeflag: tar.TypeReg, Mode: 0644, Size: 3}))
+	tw.Write([]byte("new"))
+	assert.Nil(tw.Close())
+	assert.Nil(f.Close())
+
+	assert.Nil(Untar(archive, dst, false))
+	assert.Equal("keep", GetContents(victim))
+	assert.Equal("new", GetContents(filepath.Join(dst, "name")))
+	info, err := os.Lstat(filepath.Join(dst, "name"))
+	assert.Nil(err)
+	assert.True(info.Mode().IsRegular())
+}
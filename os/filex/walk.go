@@ -0,0 +1,184 @@
+package filex
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WalkOptions 目录遍历选项
+type WalkOptions struct {
+	// SameFilesystem 不进入与 root 位于不同文件系统(设备)的子目录, 类似 find -xdev
+	// 挂载点目录自身仍会传给 fn; Windows 下不支持, 该选项无效
+	SameFilesystem bool
+}
+
+// deviceID 获取路径所在的设备号, 测试时可替换
+var deviceID = fileDevice
+
+// Walk 遍历 root 目录树, 对每个文件/目录调用 fn, 语义同 filepath.WalkDir
+func Walk(root string, fn fs.WalkDirFunc, opts ...WalkOptions) error {
+	var opt WalkOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if !opt.SameFilesystem {
+		return filepath.WalkDir(root, fn)
+	}
+	rootDev, err := deviceID(root)
+	if err != nil {
+		return err
+	}
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if ferr := fn(p, d, err); ferr != nil || err != nil || p == root || !d.IsDir() {
+			return ferr
+		}
+		dev, err := deviceID(p)
+		if err != nil {
+			return err
+		}
+		if dev != rootDev {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+}
+
+// FindCaseCollisions 查找 root 目录树中同一目录下名称仅大小写不同的文件/目录
+// 如 README 与 readme, 此类文件在大小写不敏感的文件系统中会相互覆盖
+// 返回所有冲突分组, 组内路径及各组之间均按路径排序
+func FindCaseCollisions(root string) ([][]string, error) {
+	groups := make(map[string][]string)
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == root {
+			return nil
+		}
+		key := filepath.Join(filepath.Dir(p), strings.ToLower(d.Name()))
+		groups[key] = append(groups[key], p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	var result [][]string
+	for _, g := range groups {
+		if len(g) > 1 {
+			sort.Strings(g)
+			result = append(result, g)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i][0] < result[j][0] })
+	return result, nil
+}
+
+// FindBrokenSymlinks 查找 root 目录树中目标不存在的符号链接, 遍历时不跟随链接
+func FindBrokenSymlinks(root string) ([]string, error) {
+	var links []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type()&fs.ModeSymlink == 0 {
+			return nil
+		}
+		if _, err := os.Stat(p); os.IsNotExist(err) {
+			links = append(links, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// RemoveBrokenSymlinks 删除 root 目录树中目标不存在的符号链接, 返回删除的数量
+func RemoveBrokenSymlinks(root string) (int, error) {
+	links, err := FindBrokenSymlinks(root)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, link := range links {
+		if err := os.Remove(link); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// SnapshotEntry 目录快照中单个文件/目录的状态
+type SnapshotEntry struct {
+	Size    int64
+	ModTime time.Time
+	Mode    os.FileMode
+	IsDir   bool
+}
+
+// Snapshot 生成 root 目录树快照, 键为以 / 分隔的相对路径, 不包含 root 自身
+// 符号链接不跟随, 按链接自身记录
+func Snapshot(root string) (map[string]SnapshotEntry, error) {
+	snap := make(map[string]SnapshotEntry)
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == root {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		snap[filepath.ToSlash(rel)] = SnapshotEntry{
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+			Mode:    info.Mode(),
+			IsDir:   info.IsDir(),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// SnapshotDiff 比较两个快照, 返回 b 相对 a 新增, 删除及发生变化的路径(均已排序)
+// 文件比较大小, 修改时间及权限, 目录只比较类型及权限
+func SnapshotDiff(a, b map[string]SnapshotEntry) (added, removed, changed []string) {
+	for p, eb := range b {
+		ea, ok := a[p]
+		if !ok {
+			added = append(added, p)
+			continue
+		}
+		if ea.IsDir != eb.IsDir || ea.Mode != eb.Mode {
+			changed = append(changed, p)
+			continue
+		}
+		if !ea.IsDir && (ea.Size != eb.Size || !ea.ModTime.Equal(eb.ModTime)) {
+			changed = append(changed, p)
+		}
+	}
+	for p := range a {
+		if _, ok := b[p]; !ok {
+			removed = append(removed, p)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	sort.Strings(changed)
+	return
+}
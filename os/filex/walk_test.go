@@ -0,0 +1,127 @@
+package filex
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindCaseCollisions(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	assert.Nil(PutContents(filepath.Join(dir, "README"), "upper"))
+	assert.Nil(PutContents(filepath.Join(dir, "readme"), "lower"))
+	if GetContents(filepath.Join(dir, "README")) != "upper" {
+		t.Skip("file system is case-insensitive")
+	}
+	assert.Nil(PutContents(filepath.Join(dir, "src", "Main.go"), ""))
+	assert.Nil(PutContents(filepath.Join(dir, "src", "main.go"), ""))
+	assert.Nil(PutContents(filepath.Join(dir, "src", "MAIN.GO"), ""))
+	assert.Nil(PutContents(filepath.Join(dir, "src", "other.go"), ""))
+	// 不同目录下同名不算冲突
+	assert.Nil(PutContents(filepath.Join(dir, "docs", "readme"), ""))
+
+	groups, err := FindCaseCollisions(dir)
+	assert.Nil(err)
+	assert.Equal([][]string{
+		{filepath.Join(dir, "README"), filepath.Join(dir, "readme")},
+		{filepath.Join(dir, "src", "MAIN.GO"), filepath.Join(dir, "src", "Main.go"), filepath.Join(dir, "src", "main.go")},
+	}, groups)
+}
+
+func TestBrokenSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require privileges on windows")
+	}
+	assert := assert.New(t)
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target.txt")
+	assert.Nil(PutContents(target, "x"))
+	assert.Nil(Mkdir(filepath.Join(dir, "sub")))
+	assert.Nil(os.Symlink(target, filepath.Join(dir, "valid")))
+	assert.Nil(os.Symlink(filepath.Join(dir, "gone.txt"), filepath.Join(dir, "dangling")))
+	assert.Nil(os.Symlink("../nowhere", filepath.Join(dir, "sub", "dangling")))
+	assert.Nil(os.Symlink("..", filepath.Join(dir, "sub", "loop")))
+
+	links, err := FindBrokenSymlinks(dir)
+	assert.Nil(err)
+	assert.Equal([]string{filepath.Join(dir, "dangling"), filepath.Join(dir, "sub", "dangling")}, links)
+
+	n, err := RemoveBrokenSymlinks(dir)
+	assert.Nil(err)
+	assert.Equal(2, n)
+	_, err = os.Lstat(filepath.Join(dir, "dangling"))
+	assert.True(os.IsNotExist(err))
+	_, err = os.Lstat(filepath.Join(dir, "valid"))
+	assert.Nil(err)
+	_, err = os.Lstat(filepath.Join(dir, "sub", "loop"))
+	assert.Nil(err)
+}
+
+func TestSnapshot(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	assert.Nil(PutContents(filepath.Join(dir, "a.txt"), "a"))
+	assert.Nil(PutContents(filepath.Join(dir, "sub", "b.txt"), "b"))
+	assert.Nil(PutContents(filepath.Join(dir, "sub", "c.txt"), "c"))
+
+	before, err := Snapshot(dir)
+	assert.Nil(err)
+	assert.Len(before, 4)
+	assert.True(before["sub"].IsDir)
+	assert.Equal(int64(1), before["sub/b.txt"].Size)
+
+	assert.Nil(PutContents(filepath.Join(dir, "sub", "b.txt"), "bbb"))
+	assert.Nil(os.Remove(filepath.Join(dir, "sub", "c.txt")))
+	assert.Nil(PutContents(filepath.Join(dir, "new", "d.txt"), "d"))
+	past := time.Now().Add(-time.Hour)
+	assert.Nil(os.Chtimes(filepath.Join(dir, "a.txt"), past, past))
+
+	after, err := Snapshot(dir)
+	assert.Nil(err)
+	added, removed, changed := SnapshotDiff(before, after)
+	assert.Equal([]string{"new", "new/d.txt"}, added)
+	assert.Equal([]string{"sub/c.txt"}, removed)
+	assert.Equal([]string{"a.txt", "sub/b.txt"}, changed)
+
+	added, removed, changed = SnapshotDiff(after, after)
+	assert.Empty(added)
+	assert.Empty(removed)
+	assert.Empty(changed)
+}
+
+func TestWalkSameFilesystem(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	for _, p := range []string{"a.txt", "src/b.txt", "mnt/remote/c.txt", "mnt/local.txt"} {
+		assert.Nil(PutContents(filepath.Join(dir, p), ""))
+	}
+
+	// mnt/remote 位于另一设备
+	old := deviceID
+	deviceID = func(path string) (uint64, error) {
+		if path == filepath.Join(dir, "mnt", "remote") {
+			return 2, nil
+		}
+		return 1, nil
+	}
+	defer func() { deviceID = old }()
+
+	walk := func(opts ...WalkOptions) []string {
+		var got []string
+		assert.Nil(Walk(dir, func(p string, d fs.DirEntry, err error) error {
+			assert.Nil(err)
+			rel, _ := filepath.Rel(dir, p)
+			got = append(got, filepath.ToSlash(rel))
+			return nil
+		}, opts...))
+		return got
+	}
+	assert.Equal([]string{".", "a.txt", "mnt", "mnt/local.txt", "mnt/remote", "mnt/remote/c.txt", "src", "src/b.txt"}, walk())
+	assert.Equal([]string{".", "a.txt", "mnt", "mnt/local.txt", "mnt/remote", "src", "src/b.txt"}, walk(WalkOptions{SameFilesystem: true}))
+}
@@ -0,0 +1,149 @@
+package filex
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// WatchOp 文件变化类型
+type WatchOp int
+
+const (
+	// WatchCreated 文件被创建
+	WatchCreated WatchOp = iota + 1
+	// WatchModified 文件被修改(修改时间或大小发生变化)
+	WatchModified
+	// WatchRemoved 文件被删除
+	WatchRemoved
+)
+
+func (op WatchOp) String() string {
+	switch op {
+	case WatchCreated:
+		return "created"
+	case WatchModified:
+		return "modified"
+	case WatchRemoved:
+		return "removed"
+	}
+	return "unknown"
+}
+
+// WatchEvent 文件变化事件
+type WatchEvent struct {
+	Path string
+	Op   WatchOp
+	// Info 变化后的文件信息, 文件被删除时为 nil
+	Info os.FileInfo
+}
+
+// Watch 按 interval 间隔轮询文件的修改时间及大小, 发生变化时调用 fn
+// 启动后台 goroutine 进行轮询, 返回的 stop 函数停止轮询并等待 goroutine 退出, 不要在 fn 中调用 stop
+func Watch(path string, interval time.Duration, fn func(event WatchEvent)) (stop func(), err error) {
+	if interval <= 0 {
+		return nil, errors.New("watch interval must be positive")
+	}
+	if fn == nil {
+		return nil, errors.New("watch callback is nil")
+	}
+	last, err := os.Stat(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			cur, err := os.Stat(path)
+			if err != nil {
+				cur = nil
+				if !os.IsNotExist(err) {
+					continue
+				}
+			}
+			switch {
+			case last == nil && cur != nil:
+				fn(WatchEvent{Path: path, Op: WatchCreated, Info: cur})
+			case last != nil && cur == nil:
+				fn(WatchEvent{Path: path, Op: WatchRemoved})
+			case last != nil && cur != nil && (!cur.ModTime().Equal(last.ModTime()) || cur.Size() != last.Size()):
+				fn(WatchEvent{Path: path, Op: WatchModified, Info: cur})
+			}
+			last = cur
+		}
+	}()
+
+	var once sync.Once
+	stop = func() {
+		once.Do(func() {
+			close(done)
+			<-exited
+		})
+	}
+	return stop, nil
+}
+
+// ErrWaitTimeout 等待文件超时
+var ErrWaitTimeout = errors.New("timed out waiting for file")
+
+// waitPollInterval WaitForFile 及 WaitForStable 检查文件状态的最大间隔
+const waitPollInterval = 100 * time.Millisecond
+
+// WaitForFile 轮询等待文件出现, 超过 timeout 仍不存在时返回 ErrWaitTimeout
+func WaitForFile(path string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return nil
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+		if !time.Now().Before(deadline) {
+			return ErrWaitTimeout
+		}
+		time.Sleep(min(waitPollInterval, time.Until(deadline)))
+	}
+}
+
+// WaitForStable 等待文件出现且其大小及修改时间在 quiet 时间内不再变化(通常表示写入方已完成)
+// 超过 timeout 仍未满足条件时返回 ErrWaitTimeout
+func WaitForStable(path string, quiet, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	interval := min(waitPollInterval, quiet/4)
+	if interval <= 0 {
+		interval = time.Millisecond
+	}
+	var last os.FileInfo
+	var since time.Time
+	for {
+		info, err := os.Stat(path)
+		now := time.Now()
+		switch {
+		case err == nil:
+			if last == nil || info.Size() != last.Size() || !info.ModTime().Equal(last.ModTime()) {
+				last, since = info, now
+			} else if now.Sub(since) >= quiet {
+				return nil
+			}
+		case os.IsNotExist(err):
+			last = nil
+		default:
+			return err
+		}
+		if !now.Before(deadline) {
+			return ErrWaitTimeout
+		}
+		time.Sleep(min(interval, time.Until(deadline)))
+	}
+}
@@ -0,0 +1,108 @@
+package filex
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatch(t *testing.T) {
+	assert := assert.New(t)
+	path := filepath.Join(t.TempDir(), "watched.txt")
+
+	events := make(chan WatchEvent, 10)
+	stop, err := Watch(path, 10*time.Millisecond, func(e WatchEvent) {
+		events <- e
+	})
+	assert.Nil(err)
+	defer stop()
+
+	next := func() WatchEvent {
+		select {
+		case e := <-events:
+			return e
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for watch event")
+		}
+		return WatchEvent{}
+	}
+
+	assert.Nil(PutContents(path, "hello"))
+	e := next()
+	assert.Equal(WatchCreated, e.Op)
+	assert.Equal(path, e.Path)
+
+	assert.Nil(AppendContents(path, " world"))
+	e = next()
+	assert.Equal(WatchModified, e.Op)
+	assert.Equal(int64(11), e.Info.Size())
+
+	assert.Nil(os.Remove(path))
+	e = next()
+	assert.Equal(WatchRemoved, e.Op)
+	assert.Nil(e.Info)
+
+	stop()
+	assert.Nil(PutContents(path, "after stop"))
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(events, 0)
+
+	_, err = Watch(path, 0, func(WatchEvent) {})
+	assert.NotNil(err)
+}
+
+func TestWaitForFile(t *testing.T) {
+	assert := assert.New(t)
+	p := filepath.Join(t.TempDir(), "ready")
+
+	assert.Equal(ErrWaitTimeout, WaitForFile(p, 50*time.Millisecond))
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		PutContents(p, "x")
+	}()
+	assert.Nil(WaitForFile(p, 5*time.Second))
+	assert.True(Exists(p))
+}
+
+func TestWaitForStable(t *testing.T) {
+	assert := assert.New(t)
+	p := filepath.Join(t.TempDir(), "upload.bin")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 5; i++ {
+			AppendContents(p, "chunk")
+			time.Sleep(30 * time.Millisecond)
+		}
+	}()
+	start := time.Now()
+	assert.Nil(WaitForStable(p, 150*time.Millisecond, 5*time.Second))
+	<-done
+	assert.Equal(int64(25), Size(p))
+	assert.True(time.Since(start) >= 150*time.Millisecond)
+
+	// 持续写入时超时
+	stop, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				AppendContents(p, "x")
+				time.Sleep(10 * time.Millisecond)
+			}
+		}
+	}()
+	err := WaitForStable(p, time.Second, 200*time.Millisecond)
+	close(stop)
+	<-stopped
+	assert.True(errors.Is(err, ErrWaitTimeout))
+}
@@ -0,0 +1,155 @@
+package filex
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Zip 将 srcDir 目录递归压缩为 zip 文件 dstZip, 保留相对路径, 文件权限及空目录
+// 仅处理普通文件和目录, 符号链接等特殊文件会被忽略
+func Zip(srcDir, dstZip string) error {
+	absDst, err := filepath.Abs(dstZip)
+	if err != nil {
+		return err
+	}
+	return writeStream(dstZip, 0666, func(w io.Writer) error {
+		zw := zip.NewWriter(w)
+		err := filepath.WalkDir(srcDir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if p == srcDir || !d.IsDir() && !d.Type().IsRegular() {
+				return nil
+			}
+			if abs, _ := filepath.Abs(p); abs == absDst {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(srcDir, p)
+			if err != nil {
+				return err
+			}
+			header, err := zip.FileInfoHeader(info)
+			if err != nil {
+				return err
+			}
+			header.Name = filepath.ToSlash(rel)
+			if d.IsDir() {
+				header.Name += "/"
+				header.Method = zip.Store
+				_, err = zw.CreateHeader(header)
+				return err
+			}
+			header.Method = zip.Deflate
+			fw, err := zw.CreateHeader(header)
+			if err != nil {
+				return err
+			}
+			f, err := os.Open(p)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			_, err = io.CopyBuffer(fw, f, make([]byte, copyBufferSize))
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		return zw.Close()
+	})
+}
+
+// Unzip 将 zip 文件 srcZip 解压到 dstDir 目录
+// 解压前校验所有条目, 包含绝对路径或通过 .. 跳出 dstDir 的条目(zip slip)时返回 ErrPathOutsideBase 且不解压任何文件
+func Unzip(srcZip, dstDir string) error {
+	zr, err := zip.OpenReader(srcZip)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	targets := make([]string, len(zr.File))
+	for i, f := range zr.File {
+		target, err := joinWithin(dstDir, filepath.FromSlash(f.Name))
+		if err != nil {
+			return fmt.Errorf("unzip %s: %w", f.Name, err)
+		}
+		targets[i] = target
+	}
+	var dirs []dirMode
+	for i, f := range zr.File {
+		mode := f.Mode()
+		if mode.IsDir() {
+			perm := mode.Perm()
+			if perm == 0 {
+				perm = DirPerm
+			}
+			if err := MkdirMode(targets[i], perm|0700); err != nil {
+				return err
+			}
+			dirs = append(dirs, dirMode{path: targets[i], perm: perm})
+			continue
+		}
+		if err := unzipFile(f, targets[i]); err != nil {
+			return err
+		}
+	}
+	return restoreDirs(dirs)
+}
+
+// dirMode 解包时延后设置的目录权限及修改时间
+type dirMode struct {
+	path    string
+	perm    os.FileMode
+	modTime time.Time
+}
+
+// restoreDirs 在所有条目解包完成后, 自最深层目录开始设置目录权限及修改时间(非零时)
+// 目录先以可写权限创建, 使只读目录中的文件也能写入; 修改时间在其中文件写入后设置才不会被改变
+func restoreDirs(dirs []dirMode) error {
+	sort.SliceStable(dirs, func(i, j int) bool {
+		return strings.Count(dirs[i].path, string(filepath.Separator)) > strings.Count(dirs[j].path, string(filepath.Separator))
+	})
+	for _, d := range dirs {
+		if err := os.Chmod(d.path, d.perm); err != nil {
+			return err
+		}
+		if !d.modTime.IsZero() {
+			if err := os.Chtimes(d.path, d.modTime, d.modTime); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func unzipFile(f *zip.File, target string) error {
+	mode := f.Mode()
+	if !mode.IsRegular() {
+		return nil
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	perm := mode.Perm()
+	if perm == 0 {
+		perm = 0666
+	}
+	return writeStream(target, perm, func(w io.Writer) error {
+		_, err := io.CopyBuffer(w, rc, make([]byte, copyBufferSize))
+		return err
+	})
+}
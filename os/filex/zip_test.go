@@ -0,0 +1,63 @@
+package filex
+
+import (
+	"archive/zip"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestZipRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	assert.Nil(PutContents(filepath.Join(src, "a.txt"), "a"))
+	assert.Nil(PutContents(filepath.Join(src, "sub", "deep", "b.txt"), "b"))
+	assert.Nil(Mkdir(filepath.Join(src, "empty")))
+	assert.Nil(PutContents(filepath.Join(src, "run.sh"), "#!/bin/sh"))
+	assert.Nil(os.Chmod(filepath.Join(src, "run.sh"), 0755))
+
+	archive := filepath.Join(dir, "out", "src.zip")
+	assert.Nil(Zip(src, archive))
+
+	dst := filepath.Join(dir, "dst")
+	assert.Nil(Unzip(archive, dst))
+	assert.Equal("a", GetContents(filepath.Join(dst, "a.txt")))
+	assert.Equal("b", GetContents(filepath.Join(dst, "sub", "deep", "b.txt")))
+	assert.True(IsDir(filepath.Join(dst, "empty")))
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(filepath.Join(dst, "run.sh"))
+		assert.Nil(err)
+		assert.Equal(os.FileMode(0755), info.Mode().Perm())
+	}
+}
+
+func TestUnzipTraversal(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+
+	for _, name := range []string{"../evil.txt", "ok/../../evil.txt", "/abs/evil.txt"} {
+		archive := filepath.Join(dir, "evil.zip")
+		f, err := os.Create(archive)
+		assert.Nil(err)
+		zw := zip.NewWriter(f)
+		w, err := zw.Create("good.txt")
+		assert.Nil(err)
+		w.Write([]byte("good"))
+		w, err = zw.Create(name)
+		assert.Nil(err)
+		w.Write([]byte("evil"))
+		assert.Nil(zw.Close())
+		assert.Nil(f.Close())
+
+		dst := filepath.Join(dir, "dst")
+		err = Unzip(archive, dst)
+		assert.True(errors.Is(err, ErrPathOutsideBase), name)
+		assert.False(Exists(filepath.Join(dir, "evil.txt")))
+		assert.False(Exists(filepath.Join(dst, "good.txt")), "nothing should be extracted")
+	}
+}